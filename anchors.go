@@ -0,0 +1,53 @@
+package main
+
+import (
+	"time"
+)
+
+// startOfDay - return midnight at the beginning of t's day
+func startOfDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
+
+// endOfDay - return the last whole second of t's day
+func endOfDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 23, 59, 59, 0, t.Location())
+}
+
+// startOfWeek - return midnight on the Monday of t's week, as in ISO 8601
+func startOfWeek(t time.Time) time.Time {
+	daysSinceMonday := (int(t.Weekday()) + 6) % 7
+	return startOfDay(t.AddDate(0, 0, -daysSinceMonday))
+}
+
+// anchors - @NAME values which resolve to the start or end of the current day, week, month, or year
+var anchors = map[string]func(now time.Time) time.Time{
+	"sod": startOfDay,
+	"eod": endOfDay,
+	"sow": startOfWeek,
+	"eow": func(now time.Time) time.Time {
+		return endOfDay(startOfWeek(now).AddDate(0, 0, 6))
+	},
+	"som": func(now time.Time) time.Time {
+		return time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
+	},
+	"eom": func(now time.Time) time.Time {
+		// day 0 of the next month is the last day of this month
+		return time.Date(now.Year(), now.Month()+1, 0, 23, 59, 59, 0, now.Location())
+	},
+	"soy": func(now time.Time) time.Time {
+		return time.Date(now.Year(), time.January, 1, 0, 0, 0, 0, now.Location())
+	},
+	"eoy": func(now time.Time) time.Time {
+		return time.Date(now.Year(), time.December, 31, 23, 59, 59, 0, now.Location())
+	},
+}
+
+// resolveAnchor - return the time for an anchor such as @som, and false when name is not an anchor
+func resolveAnchor(name string) (time.Time, bool) {
+	anchor, found := anchors[name]
+	if !found {
+		return time.Time{}, false
+	}
+	return anchor(startTime), true
+}
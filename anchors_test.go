@@ -0,0 +1,40 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestResolveAnchor(t *testing.T) {
+	resetState(t)
+	// a Thursday in a leap year
+	startTime = time.Date(2024, 2, 15, 13, 14, 15, 0, time.UTC)
+	tests := []struct {
+		name string
+		want time.Time
+	}{
+		{"sod", time.Date(2024, 2, 15, 0, 0, 0, 0, time.UTC)},
+		{"eod", time.Date(2024, 2, 15, 23, 59, 59, 0, time.UTC)},
+		{"sow", time.Date(2024, 2, 12, 0, 0, 0, 0, time.UTC)},
+		{"eow", time.Date(2024, 2, 18, 23, 59, 59, 0, time.UTC)},
+		{"som", time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)},
+		{"eom", time.Date(2024, 2, 29, 23, 59, 59, 0, time.UTC)},
+		{"soy", time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{"eoy", time.Date(2024, 12, 31, 23, 59, 59, 0, time.UTC)},
+	}
+	for _, tt := range tests {
+		got, found := resolveAnchor(tt.name)
+		if !found || !got.Equal(tt.want) {
+			t.Errorf("@%s = %s, %v, want %s", tt.name, got, found, tt.want)
+		}
+	}
+	if _, found := resolveAnchor("soq"); found {
+		t.Errorf("@soq was taken as an anchor")
+	}
+
+	// a Sunday is the end of its ISO week, not the start of the next
+	startTime = time.Date(2024, 2, 18, 8, 0, 0, 0, time.UTC)
+	if got, _ := resolveAnchor("sow"); !got.Equal(time.Date(2024, 2, 12, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("@sow on a Sunday = %s", got)
+	}
+}
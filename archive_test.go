@@ -0,0 +1,154 @@
+package main
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+// zipArchive - return an in-memory zip holding one entry with the given name and modify time
+func zipArchive(t *testing.T, name string, mtime time.Time) *bytes.Reader {
+	t.Helper()
+	buf := &bytes.Buffer{}
+	zw := zip.NewWriter(buf)
+	w, err := zw.CreateHeader(&zip.FileHeader{Name: name, Method: zip.Store, Modified: mtime})
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.Write([]byte("hello"))
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return bytes.NewReader(buf.Bytes())
+}
+
+func TestShowZip(t *testing.T) {
+	mtime := time.Date(2021, 3, 29, 9, 8, 6, 0, time.UTC)
+
+	buf := resetState(t)
+	opts.logfmt = true
+	r := zipArchive(t, "a.txt", mtime)
+	if err := showZip(r, r.Size()); err != nil {
+		t.Fatal(err)
+	}
+	pairs := parseLogfmt(t, strings.TrimSpace(buf.String()))
+	if pairs["name"] != "a.txt" || pairs["size"] != "5" {
+		t.Errorf("-logfmt gave %q, want a.txt of 5 bytes", buf.String())
+	}
+	if got, err := time.Parse(time.RFC3339Nano, pairs["mtime"]); err != nil || !got.Equal(mtime) {
+		t.Errorf("-logfmt gave mtime %q, want %s", pairs["mtime"], mtime)
+	}
+	if atime, found := pairs["atime"]; found {
+		t.Errorf("-logfmt gave atime %q for a zip entry, want none", atime)
+	}
+
+	buf = resetState(t)
+	opts.csv = true
+	r = zipArchive(t, "a.txt", mtime)
+	if err := showZip(r, r.Size()); err != nil {
+		t.Fatal(err)
+	}
+	writeCSV()
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 || !strings.HasSuffix(lines[1], ",") {
+		t.Errorf("-csv gave %q, want an empty atime column", buf.String())
+	}
+
+	buf = resetState(t)
+	opts.json = true
+	r = zipArchive(t, "a.txt", mtime)
+	if err := showZip(r, r.Size()); err != nil {
+		t.Fatal(err)
+	}
+	writeJSON()
+	var got []map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil || len(got) != 1 {
+		t.Fatalf("-json gave %q, %v, want one record", buf.String(), err)
+	}
+	if atime, found := got[0]["atime"]; found {
+		t.Errorf("-json gave atime %v for a zip entry, want none", atime)
+	}
+
+	buf = resetState(t)
+	opts.prometheus = true
+	r = zipArchive(t, "a.txt", mtime)
+	if err := showZip(r, r.Size()); err != nil {
+		t.Fatal(err)
+	}
+	writePrometheus()
+	if strings.Contains(buf.String(), "gostat_file_atime_seconds") || !strings.Contains(buf.String(), "gostat_file_mtime_seconds") {
+		t.Errorf("-prometheus gave %q, want an mtime metric and no atime metric", buf.String())
+	}
+
+	buf = resetState(t)
+	opts.sortKey = "a"
+	r = zipArchive(t, "a.txt", mtime)
+	if err := showZip(r, r.Size()); err != nil {
+		t.Fatal(err)
+	}
+	if buf.Len() > 0 {
+		t.Errorf("-sortkey atime gave %q for a zip entry, want nothing", buf.String())
+	}
+}
+
+func TestShowTar(t *testing.T) {
+	mtime := time.Date(2021, 3, 29, 9, 8, 6, 0, time.UTC)
+	atime := mtime.Add(time.Hour)
+	ctime := mtime.Add(2 * time.Hour)
+	archive := &bytes.Buffer{}
+	tw := tar.NewWriter(archive)
+	hdr := &tar.Header{Name: "a.txt", Mode: 0644, Size: 5, ModTime: mtime, AccessTime: atime, ChangeTime: ctime, Format: tar.FormatPAX}
+	if err := tw.WriteHeader(hdr); err != nil {
+		t.Fatal(err)
+	}
+	tw.Write([]byte("hello"))
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	buf := resetState(t)
+	opts.logfmt = true
+	if err := showTar(tar.NewReader(archive)); err != nil {
+		t.Fatal(err)
+	}
+	pairs := parseLogfmt(t, strings.TrimSpace(buf.String()))
+	for key, want := range map[string]time.Time{"mtime": mtime, "atime": atime} {
+		if got, err := time.Parse(time.RFC3339Nano, pairs[key]); err != nil || !got.Equal(want) {
+			t.Errorf("-logfmt gave %s %q, want %s", key, pairs[key], want)
+		}
+	}
+	if pairs["name"] != "a.txt" || pairs["size"] != "5" {
+		t.Errorf("-logfmt gave %q, want a.txt of 5 bytes", buf.String())
+	}
+}
+
+func TestArchiveKind(t *testing.T) {
+	ustar := make([]byte, tarMagicOffset+5)
+	copy(ustar[tarMagicOffset:], "ustar")
+	tests := []struct {
+		name string
+		head []byte
+		want string
+	}{
+		{"x.bin", []byte("PK\x03\x04rest"), "zip"},
+		{"x.bin", []byte("PK\x05\x06"), "zip"},
+		{"x.bin", []byte{0x1f, 0x8b, 0x08}, "tgz"},
+		{"x.bin", ustar, "tar"},
+		{"X.ZIP", nil, "zip"},
+		{"x.tar.gz", nil, "tgz"},
+		{"x.tgz", nil, "tgz"},
+		{"x.tar", nil, "tar"},
+	}
+	for _, test := range tests {
+		if got, err := archiveKind(test.name, test.head); err != nil || got != test.want {
+			t.Errorf("archiveKind(%q) = %q, %v, want %q", test.name, got, err, test.want)
+		}
+	}
+	if _, err := archiveKind("x.txt", []byte("plain text")); err == nil {
+		t.Error("archiveKind(x.txt) gave no error for a file which is not an archive")
+	}
+}
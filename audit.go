@@ -0,0 +1,58 @@
+package main
+
+import (
+	"os"
+	"os/user"
+	"strings"
+	"sync"
+	"time"
+)
+
+// auditLog - the file opened by -append-log, nil when it is not in use
+var auditLog *os.File
+
+// auditMutex - serializes writes so each audit line is appended whole
+var auditMutex sync.Mutex
+
+// auditUser - the name recorded as having made each change
+var auditUser string
+
+// openAuditLog - open path for appending, creating it when it does not exist
+func openAuditLog(path string) error {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0644)
+	if err != nil {
+		return err
+	}
+	auditLog = f
+	if u, err := user.Current(); err == nil {
+		auditUser = u.Username
+	} else {
+		auditUser = os.Getenv("USER")
+	}
+	return nil
+}
+
+// writeAudit - append one line recording a file's times before and after they were set
+// the line is in logfmt format: time, user, file, then the old and new value of each changed time stamp
+// time is when the change was made, read from the system clock even when -now is given
+func writeAudit(file string, oldTimes, newTimes map[string]time.Time) error {
+	if auditLog == nil {
+		return nil
+	}
+	pairs := []string{
+		"time=" + time.Now().Format(time.RFC3339Nano),
+		"user=" + logfmtValue(auditUser),
+		"file=" + logfmtValue(file),
+	}
+	for _, field := range []string{"a", "m"} {
+		if t, found := newTimes[field]; found {
+			name := fieldNames[field]
+			pairs = append(pairs, "old_"+name+"="+oldTimes[field].Format(time.RFC3339Nano), "new_"+name+"="+t.Format(time.RFC3339Nano))
+		}
+	}
+
+	auditMutex.Lock()
+	defer auditMutex.Unlock()
+	_, err := auditLog.WriteString(strings.Join(pairs, " ") + "\n")
+	return err
+}
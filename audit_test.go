@@ -0,0 +1,48 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAppendLog(t *testing.T) {
+	dir := t.TempDir()
+	before := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	file := writeFile(t, filepath.Join(dir, "a.txt"), "", before)
+	auditFile := filepath.Join(dir, "audit.log")
+	first := time.Date(2021, 3, 29, 9, 8, 7, 0, time.UTC)
+	second := first.Add(time.Hour)
+
+	for _, mtime := range []time.Time{first, second} {
+		if _, stderr, code := runGostat(t, "-append-log", auditFile, "-m", mtime.Format(time.RFC3339), file); code != 0 {
+			t.Fatalf("setting %s exited %d: %s", mtime, code, stderr)
+		}
+	}
+
+	data, err := os.ReadFile(auditFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("two sets wrote %d audit lines: %q", len(lines), data)
+	}
+	changes := [][2]time.Time{{before, first}, {first, second}}
+	for i, line := range lines {
+		pairs := parseLogfmt(t, line)
+		oldTime, _ := time.Parse(time.RFC3339Nano, pairs["old_mtime"])
+		newTime, _ := time.Parse(time.RFC3339Nano, pairs["new_mtime"])
+		if pairs["file"] != file || !oldTime.Equal(changes[i][0]) || !newTime.Equal(changes[i][1]) {
+			t.Errorf("audit line %d = %q, want %s changed from %s to %s", i, line, file, changes[i][0], changes[i][1])
+		}
+		if _, err := time.Parse(time.RFC3339Nano, pairs["time"]); err != nil {
+			t.Errorf("audit line %d has an invalid time: %s", i, err)
+		}
+		if _, found := pairs["old_atime"]; found {
+			t.Errorf("audit line %d records an access time which was not set: %q", i, line)
+		}
+	}
+}
@@ -0,0 +1,34 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+)
+
+// batchWriter - buffers output between batches with -batch-size, otherwise nil
+var batchWriter *bufio.Writer
+
+// startBatches - buffer output so that it is written once every opts.batchSize files
+func startBatches() {
+	batchWriter = bufio.NewWriter(output)
+	output = batchWriter
+}
+
+// endBatch - write out buffered output and, with -verbose, report progress
+func endBatch(processed, total int) {
+	flushBatch()
+	if opts.verbose {
+		fmt.Fprintf(os.Stderr, "processed %d of %d files\n", processed, total)
+	}
+}
+
+// flushBatch - write out any output buffered by startBatches
+func flushBatch() {
+	if batchWriter == nil {
+		return
+	}
+	if err := batchWriter.Flush(); err != nil {
+		logError("Output Error: %s\n", err)
+	}
+}
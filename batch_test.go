@@ -0,0 +1,39 @@
+package main
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// writeSpy - records how many files were shown by each write to it
+type writeSpy struct {
+	files []int
+}
+
+func (w *writeSpy) Write(p []byte) (int, error) {
+	w.files = append(w.files, strings.Count(string(p), "name  : "))
+	return len(p), nil
+}
+
+func TestBatchSize(t *testing.T) {
+	resetState(t)
+	t.Cleanup(func() { batchWriter = nil })
+	spy := &writeSpy{}
+	output = spy
+	opts.batchSize = 2
+	mtime := time.Date(2021, 3, 29, 9, 8, 7, 0, time.UTC)
+	files := tempFiles(t, 5)
+	setMtime := func(rec fileRecord) (map[string]time.Time, error) {
+		return map[string]time.Time{"m": mtime}, nil
+	}
+
+	startBatches()
+	setFileTime(context.Background(), files, setMtime)
+	flushBatch()
+	if got, want := spy.files, []int{2, 2, 1}; !reflect.DeepEqual(got, want) {
+		t.Errorf("-batch-size 2 wrote %v files at a time, want %v", got, want)
+	}
+}
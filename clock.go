@@ -0,0 +1,36 @@
+package main
+
+import "time"
+
+// clock - the source of the current time, so that everything asking for now can be given a fixed time
+type clock interface {
+	Now() time.Time
+}
+
+// systemClock - a clock reading the computer's clock
+type systemClock struct{}
+
+// Now - return the current time
+func (systemClock) Now() time.Time {
+	return time.Now()
+}
+
+// fixedClock - a clock which is always at the same time, used by -now
+type fixedClock struct {
+	t time.Time
+}
+
+// Now - return the fixed time
+func (c fixedClock) Now() time.Time {
+	return c.t
+}
+
+// currentClock - where the current time used to resolve now and file ages comes from, replaced by -now
+// records of when something happened, such as -append-log lines, use the system clock instead
+var currentClock clock = systemClock{}
+
+// setClock - make c the source of the current time, and set startTime from it
+func setClock(c clock) {
+	currentClock = c
+	startTime = c.Now().Round(0)
+}
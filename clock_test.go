@@ -0,0 +1,69 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFixedClockAge(t *testing.T) {
+	buf := resetState(t)
+	now := time.Date(2025, 1, 10, 12, 0, 0, 0, time.UTC)
+	setClock(fixedClock{t: now})
+	if !startTime.Equal(now) {
+		t.Fatalf("setClock gave startTime %s, want %s", startTime, now)
+	}
+
+	if got := fileAge(now.Add(-90 * time.Minute)); got != 90*time.Minute {
+		t.Errorf("fileAge of 90 minutes before the clock = %s", got)
+	}
+	if got := fileAge(now.Add(time.Hour)); got != -time.Hour {
+		t.Errorf("fileAge of an hour after the clock = %s", got)
+	}
+
+	opts.stale = 24 * time.Hour
+	records = []fileRecord{
+		{Name: "old.txt", Mtime: now.Add(-25 * time.Hour)},
+		{Name: "new.txt", Mtime: now.Add(-23 * time.Hour)},
+	}
+	if count := writeStale(); count != 1 || buf.String() != "old.txt: modified 25h0m0s ago\n" {
+		t.Errorf("writeStale = %d, %q, want only old.txt", count, buf.String())
+	}
+}
+
+func TestFixedClockFuture(t *testing.T) {
+	resetState(t)
+	now := time.Date(2025, 1, 10, 12, 0, 0, 0, time.UTC)
+	setClock(fixedClock{t: now})
+
+	got, err := futureTimes(fileRecord{Name: "a.txt", Mtime: now.Add(time.Second), Atime: now.Add(-time.Hour)})
+	if err != nil || len(got) != 1 || !got["m"].Equal(now) {
+		t.Errorf("futureTimes of a modify time a second after the clock = %v, %v, want only mtime set to %s", got, err, now)
+	}
+	if got, err := futureTimes(fileRecord{Name: "b.txt", Mtime: now, Atime: now.Add(-time.Hour)}); err != nil || got != nil {
+		t.Errorf("futureTimes of times at or before the clock = %v, %v, want the file skipped", got, err)
+	}
+}
+
+func TestAuditTimeIgnoresNow(t *testing.T) {
+	dir := t.TempDir()
+	file := writeFile(t, filepath.Join(dir, "a.txt"), "", time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC))
+	auditFile := filepath.Join(dir, "audit.log")
+
+	before := time.Now().Add(-time.Second)
+	if _, stderr, code := runGostat(t, "-now", "20000101.000000", "-append-log", auditFile, "-m", "now", file); code != 0 {
+		t.Fatalf("exit code %d: %s", code, stderr)
+	}
+	after := time.Now().Add(time.Second)
+
+	data, err := os.ReadFile(auditFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	when, err := time.Parse(time.RFC3339Nano, parseLogfmt(t, strings.TrimSpace(string(data)))["time"])
+	if err != nil || when.Before(before) || when.After(after) {
+		t.Errorf("audit line %q has time %s, want the real time of the change", data, when)
+	}
+}
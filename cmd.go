@@ -1,5 +1,5 @@
 /*
-gostat.go
+cmd.go
 -John Taylor
 Mar-29-2021
 
@@ -11,13 +11,24 @@ LICENSE: MIT License
 package main
 
 import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
 	"flag"
 	"fmt"
+	"io"
 	"log"
 	"os"
+	"os/signal"
+	"os/user"
 	"path/filepath"
 	"regexp"
+	"slices"
+	"sort"
 	"strconv"
+	"strings"
+	"text/tabwriter"
 	"time"
 
 	"github.com/djherbis/times"
@@ -29,22 +40,223 @@ const pgmURL string = "https://github.com/jftuga/gostat"
 const pgmLicense = "https://github.com/jftuga/gostat/blob/main/LICENSE"
 const pgmVersion string = "1.0.2"
 
+// exitInterrupted - exit status when SIGINT stops processing early, following the 128+signal convention
+const exitInterrupted int = 130
+
+// options - command-line settings which alter how files are displayed and modified
+type options struct {
+	limit      int
+	maxResults int
+	verbose    bool
+	exec       []string
+	create     bool
+	confirm    bool
+	xattr      bool
+	json       bool
+	jsonPretty bool
+	jsonl      bool
+	timeout    time.Duration
+	table      bool
+	minSize    int64
+	maxSize    int64
+	precision  bool
+	stdin      bool
+	stdinField int
+	stdinDelim string
+	verify     bool
+	byDay      bool
+
+	symlinkDetail bool
+	naturalSort   bool
+	tolerance     time.Duration
+	stale         time.Duration
+	recursive     bool
+	dirsOnly      bool
+	filesOnly     bool
+	fromMetadata  bool
+	showCommand   bool
+	dryRun        bool
+	retry         int
+	minInterval   time.Duration
+	update        bool
+	validateAll   bool
+	explain       bool
+	findDupes     bool
+	dupesSize     bool
+	showType      bool
+	types         string
+	logfmt        bool
+	protectNewer  time.Duration
+	rfc3339       bool
+	realpath      bool
+	groupByDir    bool
+	noSize        bool
+	summaryJSON   bool
+	ignore        []ignorePattern
+	format        string
+	touchDirs     bool
+	amSkew        bool
+	tz            string
+	uniq          string
+
+	// compareFields limits -verify and -assert-unchanged to these fields, nil to use their defaults
+	compareFields []string
+	sortKey       string
+	relNewest     bool
+
+	// createdAfter and createdBefore are zero when not given
+	createdAfter   time.Time
+	createdBefore  time.Time
+	createdMissing bool
+	jobs           int
+	quietErrors    bool
+	snapshot       map[string]fileRecord
+	epochUnit      string
+	header         string
+	footer         string
+
+	// hostname is only set with -hostname
+	hostname string
+	roots    stringList
+	csv      bool
+	locale   string
+	lenient  bool
+
+	// batchSize is the number of files set between flushes of the output, 0 to write it immediately
+	batchSize int
+
+	// uid and gid are -1 when -user and -group are not given
+	uid int64
+	gid int64
+
+	histogram bool
+	force     bool
+	byExt     bool
+	tree      bool
+
+	// dupesDigest is the hash algorithm given to -dupes-by-content
+	dupesDigest string
+	prometheus  bool
+	ageDays     bool
+
+	reportCapabilities bool
+	sameWeekday        bool
+
+	// inclusive makes time boundaries match files exactly at them, which are excluded by default
+	inclusive bool
+
+	// maxDepth limits how far beneath a directory -R and ** descend, -1 for no limit
+	maxDepth int
+
+	// canonicalTimes truncates displayed times to whole seconds, files are not changed
+	canonicalTimes bool
+}
+
+var opts options = options{minSize: -1, maxSize: -1, uid: -1, gid: -1, maxDepth: -1}
+
+// output - where file times are written, STDOUT unless -o is given
+var output io.Writer = os.Stdout
+
+// collect - return true when files are gathered and output all at once instead of one at a time
+func (o options) collect() bool {
+	return o.json || o.table || o.byDay || o.stale > 0 || o.findDupes || o.groupByDir || o.summaryJSON || len(o.uniq) > 0 || o.relNewest || o.snapshot != nil || o.csv || o.histogram || o.byExt || o.tree || len(o.dupesDigest) > 0 || o.prometheus || o.ageDays || o.reportCapabilities
+}
+
+// logError - log a non-fatal error or warning unless -quiet-errors was given
+// counts and exit codes are unaffected, only the message is suppressed
+func logError(format string, v ...any) {
+	if opts.quietErrors {
+		return
+	}
+	log.Printf(format, v...)
+}
+
+// stringList - a flag which can be given more than once, collecting each value
+type stringList []string
+
+func (l *stringList) String() string {
+	return strings.Join(*l, ", ")
+}
+
+func (l *stringList) Set(value string) error {
+	*l = append(*l, value)
+	return nil
+}
+
+// createdFiles - files which did not exist until -create made them
+var createdFiles = make(map[string]bool)
+
+// expandTilde - replace a leading ~ or ~user with that user's home directory
+// the shell normally does this, but not when the argument is quoted
+func expandTilde(path string) (string, error) {
+	if !strings.HasPrefix(path, "~") {
+		return path, nil
+	}
+	name, rest := path[1:], ""
+	if i := strings.IndexAny(name, `/`+string(filepath.Separator)); i >= 0 {
+		name, rest = name[:i], name[i:]
+	}
+	var home string
+	if len(name) == 0 {
+		dir, err := os.UserHomeDir()
+		if err != nil {
+			return path, err
+		}
+		home = dir
+	} else {
+		u, err := user.Lookup(name)
+		if err != nil {
+			return path, err
+		}
+		home = u.HomeDir
+	}
+	return home + rest, nil
+}
+
 // expandGlobs - expand file wildcards into a list of file names
 func expandGlobs(args []string) []string {
 	var allFiles []string
 	for _, glob := range args {
-		globbed, err := filepath.Glob(glob)
+		if glob == stdinName {
+			allFiles = append(allFiles, glob)
+			continue
+		}
+		glob, err := expandTilde(glob)
 		if err != nil {
-			log.Printf("Glob Error: %s\n", err)
+			logError("Tilde Error: %s\n", err)
 			continue
 		}
-		for _, file := range globbed {
-			allFiles = append(allFiles, file)
+		for _, pattern := range withRoots(glob) {
+			glob := filepath.Glob
+			if strings.Contains(pattern, "**") {
+				glob = expandDoubleStar
+			}
+			globbed, err := glob(pattern)
+			if err != nil {
+				logError("Glob Error: %s\n", err)
+				continue
+			}
+			for _, file := range globbed {
+				allFiles = append(allFiles, file)
+			}
 		}
 	}
 	return allFiles
 }
 
+// withRoots - return a relative path joined to each -root directory
+// absolute paths, and all paths when no -root was given, are returned as is
+func withRoots(path string) []string {
+	if len(opts.roots) == 0 || filepath.IsAbs(path) {
+		return []string{path}
+	}
+	rooted := make([]string, 0, len(opts.roots))
+	for _, root := range opts.roots {
+		rooted = append(rooted, filepath.Join(root, path))
+	}
+	return rooted
+}
+
 // Format - add thousands commas to an integer
 // https://stackoverflow.com/a/31046325/452281
 func Format(n int64) string {
@@ -72,14 +284,49 @@ func Format(n int64) string {
 	}
 }
 
+// createMissing - create empty files for arguments which do not contain wildcards and do not exist
+func createMissing(args []string) {
+	for _, file := range args {
+		if strings.ContainsAny(file, "*?[") || file == stdinName {
+			continue
+		}
+		file, err := expandTilde(file)
+		if err != nil {
+			logError("Tilde Error: %s\n", err)
+			continue
+		}
+		for _, file := range withRoots(file) {
+			if _, err := os.Stat(file); !os.IsNotExist(err) {
+				continue
+			}
+			if dir, found := isProtected(file); found && !opts.force {
+				logError("Create Error: %s is in the protected directory %s, use -force to allow this\n", file, dir)
+				continue
+			}
+			f, err := os.OpenFile(file, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0666)
+			if err != nil {
+				logError("Create Error: %s\n", err)
+				continue
+			}
+			f.Close()
+			createdFiles[file] = true
+		}
+	}
+}
+
 // getFileTimes - return a small map containing time metadata for a single file
 func getFileTimes(file string) map[string]time.Time {
-	fileTimes := make(map[string]time.Time)
 	t, err := times.Stat(file)
 	if err != nil {
-		log.Printf("getFileTimes Error: %s\n", err.Error())
-		return fileTimes
+		logError("getFileTimes Error: %s\n", err.Error())
+		return make(map[string]time.Time)
 	}
+	return timespecToMap(t)
+}
+
+// timespecToMap - convert times returned by the times library into the map returned by getFileTimes
+func timespecToMap(t times.Timespec) map[string]time.Time {
+	fileTimes := make(map[string]time.Time)
 	fileTimes["a"] = t.AccessTime()
 	fileTimes["m"] = t.ModTime()
 
@@ -92,85 +339,1045 @@ func getFileTimes(file string) map[string]time.Time {
 	return fileTimes
 }
 
+// showCapabilities - output which time stamps the file system exposes for a file and which ones gostat can set
+func showCapabilities(file string) error {
+	t, err := times.Stat(file)
+	if err != nil {
+		return err
+	}
+	yesNo := func(b bool) string {
+		if b {
+			return "yes"
+		}
+		return "no"
+	}
+	fmt.Fprintf(output, "name   : %s\n", file)
+	fmt.Fprintf(output, "access : yes, can be set\n")
+	fmt.Fprintf(output, "modify : yes, can be set\n")
+	fmt.Fprintf(output, "birth  : %s, can not be set\n", yesNo(t.HasBirthTime()))
+	fmt.Fprintf(output, "change : %s, can not be set\n", yesNo(t.HasChangeTime()))
+	return nil
+}
+
 // showFileTimes - output file name, size; birth, create, modify, and access times
-func showFileTimes(args []string) int {
-	var fi os.FileInfo
-	var err error
+// when opts.limit is set, stop after that many files have been displayed, and
+// when opts.maxResults is set, also say that the output was cut short if any more files would have been
+// no new files are started once ctx is cancelled
+func showFileTimes(ctx context.Context, allFiles []string) int {
 	count := 0
-	for _, file := range expandGlobs(args) {
-		fmt.Printf("name  : %s\n", file)
-		fi, err = os.Stat(file)
+	// without -j, files are stat'ed one at a time just before being displayed
+	chunk := 1
+	if opts.jobs > 1 {
+		chunk = opts.jobs * statChunkSize
+	}
+	var results []statResult
+	for i, file := range allFiles {
+		if opts.limit > 0 && count == opts.limit {
+			break
+		}
+		if ctx.Err() != nil {
+			reportInterrupted(i, len(allFiles))
+			break
+		}
+		if i%chunk == 0 {
+			results = statAll(allFiles[i:min(i+chunk, len(allFiles))])
+		}
+		rec, err := results[i%chunk].rec, results[i%chunk].err
 		if err != nil {
-			log.Printf("Lstat Error: %s\n", err)
+			logError("Lstat Error: %s\n", err)
+			continue
+		}
+		if !selected(rec) {
 			continue
 		}
+		if opts.maxResults > 0 && count == opts.maxResults {
+			reportTruncated()
+			break
+		}
+		displayRecord(rec, nil)
 		count += 1
-		fmt.Printf("size  : %s\n", Format(fi.Size()))
-		t := getFileTimes(file)
-		if b, found := t["b"]; found {
-			fmt.Printf("btime : %s\n", b)
+		if opts.exec != nil {
+			runExec(opts.exec, file)
 		}
-		if c, found := t["c"]; found {
-			fmt.Printf("ctime : %s\n", c)
+	}
+	if opts.verbose {
+		fmt.Fprintf(os.Stderr, "displayed %d of %d matched files\n", count, len(allFiles))
+	}
+	return count
+}
+
+// xattr - the name and size of an extended attribute
+// size is -1 when it could not be read
+type xattr struct {
+	name string
+	size int
+}
+
+// errXattrUnavailable - returned by listXattrs on platforms without extended attribute support
+var errXattrUnavailable = errors.New("unavailable")
+
+// showXattrs - output the names and sizes of a file's extended attributes
+func showXattrs(file string) {
+	attrs, err := listXattrs(file)
+	if err == errXattrUnavailable {
+		fmt.Fprintf(output, "xattr : unavailable\n")
+		return
+	}
+	if err != nil {
+		logError("listXattrs Error: %s\n", err)
+		return
+	}
+	if len(attrs) == 0 {
+		fmt.Fprintf(output, "xattr : none\n")
+		return
+	}
+	for _, attr := range attrs {
+		if attr.size < 0 {
+			fmt.Fprintf(output, "xattr : %s\n", attr.name)
+			continue
 		}
-		fmt.Printf("mtime : %s\n", t["m"])
-		fmt.Printf("atime : %s\n", t["a"])
+		fmt.Fprintf(output, "xattr : %s (%s bytes)\n", attr.name, Format(int64(attr.size)))
+	}
+}
 
-		fmt.Println()
+// formatTime - return a time as it should be displayed, the single place where the display format is chosen
+func formatTime(t time.Time) string {
+	switch opts.format {
+	case "julian":
+		return strconv.FormatFloat(julianDay(t), 'f', 8, 64)
+	case "isoweek":
+		return isoWeekDate(t)
+	case "rfc3339":
+		return t.Format(time.RFC3339Nano)
 	}
-	return count
+	if len(opts.locale) > 0 {
+		return formatLocale(t)
+	}
+	return t.String()
+}
+
+// timeFormats - the values accepted by -format
+var timeFormats = []string{"rfc3339", "julian", "isoweek"}
+
+// julianDay - return the Julian day number of t, including the fraction of the day
+// Julian days start at noon UTC and the Unix epoch is Julian day 2440587.5
+func julianDay(t time.Time) float64 {
+	return float64(t.UnixNano())/float64(24*time.Hour) + 2440587.5
+}
+
+// isoWeekDate - return t as an ISO 8601 week date, such as 2025-W01-3T15:04:05-05:00
+// the week year can differ from the calendar year in the first and last days of a year
+func isoWeekDate(t time.Time) string {
+	year, week := t.ISOWeek()
+	weekday := int(t.Weekday())
+	if weekday == 0 {
+		weekday = 7
+	}
+	return fmt.Sprintf("%04d-W%02d-%dT%s", year, week, weekday, t.Format("15:04:05.999999999Z07:00"))
+}
+
+// annotate - return " (changed)" or " (unchanged)" when comparing against a previous time stamp
+// an empty string is returned when there is nothing to compare against
+func annotate(prev map[string]time.Time, field string, t time.Time) string {
+	if prev == nil {
+		return ""
+	}
+	if p, found := prev[field]; found && p.Equal(t) {
+		return " (unchanged)"
+	}
+	return " (changed)"
+}
+
+// fileRecord - the metadata displayed for a single file
+type fileRecord struct {
+	Name  string     `json:"name"`
+	Host  string     `json:"hostname,omitempty"`
+	Size  int64      `json:"size"`
+	Btime *time.Time `json:"btime,omitempty"`
+	Ctime *time.Time `json:"ctime,omitempty"`
+	Mtime time.Time  `json:"mtime"`
+	Atime time.Time  `json:"atime"`
+
+	RealPath  string      `json:"realpath,omitempty"`
+	Type      string      `json:"type,omitempty"`
+	Precision string      `json:"precision,omitempty"`
+	Skew      string      `json:"am_skew,omitempty"`
+	Link      *linkRecord `json:"link,omitempty"`
+
+	// mode is from os.Lstat and only set when needed by -show-type, -type, or -dupes-by-content
+	mode os.FileMode
+	// uid and gid are only set when needed by -user or -group, and are -1 when unknown
+	uid int64
+	gid int64
+}
+
+// MarshalJSON - encode rec with the atime key left out when the access time is unknown, as for zip entries
+func (rec fileRecord) MarshalJSON() ([]byte, error) {
+	type plain fileRecord
+	var atime *time.Time
+	if !rec.Atime.IsZero() {
+		atime = &rec.Atime
+	}
+	return json.Marshal(struct {
+		plain
+		Atime *time.Time `json:"atime,omitempty"`
+	}{plain(rec), atime})
+}
+
+// linkRecord - the times of a symbolic link itself, as opposed to the file it points to
+type linkRecord struct {
+	Target string     `json:"target"`
+	Btime  *time.Time `json:"btime,omitempty"`
+	Ctime  *time.Time `json:"ctime,omitempty"`
+	Mtime  time.Time  `json:"mtime"`
+	Atime  time.Time  `json:"atime"`
+}
+
+// newLinkRecord - return the target and own times of a symbolic link, or nil when file is not a link
+func newLinkRecord(file string) (*linkRecord, error) {
+	fi, err := os.Lstat(file)
+	if err != nil || fi.Mode()&os.ModeSymlink == 0 {
+		return nil, err
+	}
+	target, err := os.Readlink(file)
+	if err != nil {
+		return nil, err
+	}
+	lt, err := times.Lstat(file)
+	if err != nil {
+		return nil, err
+	}
+	t := timespecToMap(lt)
+	link := &linkRecord{Target: target, Mtime: t["m"], Atime: t["a"]}
+	if b, found := t["b"]; found {
+		link.Btime = &b
+	}
+	if c, found := t["c"]; found {
+		link.Ctime = &c
+	}
+	return link, nil
+}
+
+// records - files collected for -json and -table output, which is written once all files are processed
+var records = []fileRecord{}
+
+// newFileRecord - return the size and times of a single file
+func newFileRecord(file string) (fileRecord, error) {
+	var fi os.FileInfo
+	var t map[string]time.Time
+	var err error
+	if file == stdinName {
+		fi, t, err = statStdin()
+	} else if fi, err = os.Stat(file); err == nil {
+		t = getFileTimes(file)
+	}
+	if err != nil {
+		return fileRecord{}, err
+	}
+	rec := fileRecord{Name: file, Host: opts.hostname, Size: fi.Size(), Mtime: t["m"], Atime: t["a"]}
+	if b, found := t["b"]; found {
+		rec.Btime = &b
+	}
+	if c, found := t["c"]; found {
+		rec.Ctime = &c
+	}
+	if opts.showType || len(opts.types) > 0 || len(opts.dupesDigest) > 0 {
+		// STDIN has no path to Lstat, but it is never a symbolic link
+		lfi := fi
+		if file != stdinName {
+			if lfi, err = os.Lstat(file); err != nil {
+				return fileRecord{}, err
+			}
+		}
+		rec.mode = lfi.Mode()
+		if opts.showType {
+			rec.Type = fileType(rec.mode)
+		}
+	}
+	if opts.uid >= 0 || opts.gid >= 0 {
+		rec.uid, rec.gid = fileOwner(fi)
+	}
+	if opts.realpath && file != stdinName {
+		rec.RealPath = realPath(file)
+	}
+	if opts.precision {
+		rec.Precision = timePrecision(rec.Mtime)
+	}
+	if opts.amSkew {
+		// positive when the file was read after it was last written
+		rec.Skew = rec.Atime.Sub(rec.Mtime).String()
+	}
+	if opts.symlinkDetail && file != stdinName {
+		if rec.Link, err = newLinkRecord(file); err != nil {
+			logError("Lstat Error: %s\n", err)
+		}
+	}
+	return rec, nil
 }
 
-// convertStr - convert a string to an int
-func convertStr(location string, s string) int {
-	i, err := strconv.Atoi(s)
+// realPath - return the absolute path of file with all symbolic links resolved
+// an empty string is returned, and a warning logged, when it can not be resolved
+func realPath(file string) string {
+	resolved, err := filepath.EvalSymlinks(file)
+	if err == nil {
+		resolved, err = filepath.Abs(resolved)
+	}
 	if err != nil {
-		log.Fatalf("Invalid %s: %s\n", location, s)
+		logError("Realpath Warning: %s\n", err)
+		return ""
+	}
+	return resolved
+}
+
+// fileKind - return the -type letter and a description of the kind of file, as given by its mode bits
+// the letter is 0 for kinds that -type cannot select
+func fileKind(mode os.FileMode) (rune, string) {
+	switch {
+	case mode.IsRegular():
+		return 'f', "regular file"
+	case mode.IsDir():
+		return 'd', "directory"
+	case mode&os.ModeSymlink != 0:
+		return 'l', "symlink"
+	case mode&os.ModeNamedPipe != 0:
+		return 'p', "fifo"
+	case mode&os.ModeSocket != 0:
+		return 's', "socket"
+	case mode&os.ModeCharDevice != 0:
+		return 'c', "character device"
+	case mode&os.ModeDevice != 0:
+		return 'b', "block device"
+	}
+	return 0, "irregular file"
+}
+
+// fileType - return a description of the kind of file, as given by its mode bits
+func fileType(mode os.FileMode) string {
+	_, label := fileKind(mode)
+	return label
+}
+
+// timePrecision - return the apparent resolution of a stored time stamp, judged by its trailing zero digits
+// a time that happens to fall on a boundary will appear coarser than the file system really is
+func timePrecision(t time.Time) string {
+	ns := t.Nanosecond()
+	switch {
+	case ns == 0:
+		return "seconds"
+	case ns%1e6 == 0:
+		return "milliseconds"
+	case ns%1e3 == 0:
+		return "microseconds"
+	case ns%100 == 0:
+		return "100 nanoseconds"
+	}
+	return "nanoseconds"
+}
+
+// times - return the record's time stamps keyed the same way as getFileTimes
+// the access time is left out when unknown, as it is for zip entries
+func (rec fileRecord) times() map[string]time.Time {
+	t := map[string]time.Time{"m": rec.Mtime}
+	if !rec.Atime.IsZero() {
+		t["a"] = rec.Atime
+	}
+	if rec.Btime != nil {
+		t["b"] = *rec.Btime
+	}
+	if rec.Ctime != nil {
+		t["c"] = *rec.Ctime
+	}
+	return t
+}
+
+// canonical - return a copy of rec with every time truncated to whole seconds, for -canonical-times
+func (rec fileRecord) canonical() fileRecord {
+	truncate := func(t *time.Time) *time.Time {
+		if t == nil {
+			return nil
+		}
+		whole := t.Truncate(time.Second)
+		return &whole
+	}
+	rec.Btime, rec.Ctime = truncate(rec.Btime), truncate(rec.Ctime)
+	rec.Mtime, rec.Atime = rec.Mtime.Truncate(time.Second), rec.Atime.Truncate(time.Second)
+	if rec.Link != nil {
+		link := *rec.Link
+		link.Btime, link.Ctime = truncate(link.Btime), truncate(link.Ctime)
+		link.Mtime, link.Atime = link.Mtime.Truncate(time.Second), link.Atime.Truncate(time.Second)
+		rec.Link = &link
+	}
+	return rec
+}
+
+// canonicalTimes - return a copy of a map from getFileTimes with every time truncated to whole seconds
+func canonicalTimes(fileTimes map[string]time.Time) map[string]time.Time {
+	if fileTimes == nil {
+		return nil
+	}
+	whole := make(map[string]time.Time, len(fileTimes))
+	for field, t := range fileTimes {
+		whole[field] = t.Truncate(time.Second)
+	}
+	return whole
+}
+
+// errTimedOut - returned by statFileOnce when a file takes longer than opts.timeout
+var errTimedOut = errors.New("timed out")
+
+// statFile - return newFileRecord(file), trying again up to opts.retry times when it fails
+func statFile(file string) (fileRecord, error) {
+	if opts.retry > 0 {
+		var rec fileRecord
+		err := withRetry(file, func() error {
+			var err error
+			rec, err = statFileOnce(file)
+			return err
+		})
+		return rec, err
+	}
+	return statFileOnce(file)
+}
+
+// readRecord - reads a file's times for statFileOnce, which tests replace to simulate slow file systems
+var readRecord = newFileRecord
+
+// statFileOnce - return readRecord(file), giving up after opts.timeout when it is set
+// a goroutine blocked on a hung network file system is abandoned instead of waited on
+func statFileOnce(file string) (fileRecord, error) {
+	throttle()
+	if opts.timeout <= 0 {
+		return readRecord(file)
+	}
+	type result struct {
+		rec fileRecord
+		err error
+	}
+	done := make(chan result, 1)
+	// read before starting the goroutine, which may outlive this call
+	read := readRecord
+	go func() {
+		rec, err := read(file)
+		done <- result{rec, err}
+	}()
+	select {
+	case r := <-done:
+		return r.rec, r.err
+	case <-time.After(opts.timeout):
+		return fileRecord{}, fmt.Errorf("%s: %w after %s", file, errTimedOut, opts.timeout)
+	}
+}
+
+// displayRecord - output the times for a single file
+// when prev is not nil, each time is annotated with whether it differs from prev
+func displayRecord(rec fileRecord, prev map[string]time.Time) {
+	shownCount += 1
+	shownSize += rec.Size
+	if opts.canonicalTimes {
+		rec = rec.canonical()
+		prev = canonicalTimes(prev)
+	}
+	if opts.collect() {
+		records = append(records, rec)
+		return
+	}
+	printRecord(rec, prev)
+}
+
+// printRecord - output a single file's times as text, with -logfmt as key=value pairs, with -jsonl as a JSON line,
+// or with -sortkey as a sortable time stamp and name
+func printRecord(rec fileRecord, prev map[string]time.Time) {
+	if opts.logfmt {
+		fmt.Fprintln(output, logfmtLine(rec))
+		return
+	}
+	if opts.jsonl {
+		writeJSONLine(rec)
+		return
+	}
+	if len(opts.sortKey) > 0 {
+		if t, found := rec.times()[opts.sortKey]; found {
+			fmt.Fprintf(output, "%s %s\n", t.UTC().Format(sortKeyLayout), rec.Name)
+		}
+		return
+	}
+
+	fmt.Fprintf(output, "name  : %s\n", rec.Name)
+	if len(rec.Host) > 0 {
+		fmt.Fprintf(output, "host  : %s\n", rec.Host)
+	}
+	if len(rec.RealPath) > 0 {
+		fmt.Fprintf(output, "real  : %s\n", rec.RealPath)
+	}
+	if !opts.noSize {
+		fmt.Fprintf(output, "size  : %s\n", Format(rec.Size))
+	}
+	if len(rec.Type) > 0 {
+		fmt.Fprintf(output, "type  : %s\n", rec.Type)
+	}
+	if rec.Btime != nil {
+		fmt.Fprintf(output, "%-6s: %s%s\n", birthLabel, formatTime(*rec.Btime), annotate(prev, "b", *rec.Btime))
+	}
+	if rec.Ctime != nil {
+		fmt.Fprintf(output, "%-6s: %s%s\n", changeLabel, formatTime(*rec.Ctime), annotate(prev, "c", *rec.Ctime))
 	}
-	return i
+	fmt.Fprintf(output, "mtime : %s%s\n", formatTime(rec.Mtime), annotate(prev, "m", rec.Mtime))
+	if !rec.Atime.IsZero() {
+		// only archive entries can be without an access time
+		fmt.Fprintf(output, "atime : %s%s\n", formatTime(rec.Atime), annotate(prev, "a", rec.Atime))
+	}
+	if len(rec.Precision) > 0 {
+		fmt.Fprintf(output, "prec  : %s\n", rec.Precision)
+	}
+	if len(rec.Skew) > 0 {
+		fmt.Fprintf(output, "skew  : %s\n", rec.Skew)
+	}
+	if rec.Link != nil {
+		fmt.Fprintf(output, "link  : %s\n", rec.Link.Target)
+		if rec.Link.Btime != nil {
+			fmt.Fprintf(output, "l%-5s: %s\n", birthLabel, formatTime(*rec.Link.Btime))
+		}
+		if rec.Link.Ctime != nil {
+			fmt.Fprintf(output, "l%-5s: %s\n", changeLabel, formatTime(*rec.Link.Ctime))
+		}
+		fmt.Fprintf(output, "lmtime: %s\n", formatTime(rec.Link.Mtime))
+		fmt.Fprintf(output, "latime: %s\n", formatTime(rec.Link.Atime))
+	}
+	if opts.xattr {
+		if rec.Name != stdinName {
+			showXattrs(rec.Name)
+		}
+	}
+
+	fmt.Fprintln(output)
 }
 
-// createDate - return a time.Time value when given a string in YYYYMMDD.HHMMSS format
-func createDate(dt string) time.Time {
-	year := convertStr("year", dt[0:4])
-	month := convertStr("month", dt[4:6])
-	day := convertStr("day", dt[6:8])
-	hour := convertStr("hour", dt[9:11])
-	minute := convertStr("minute", dt[11:13])
-	second := convertStr("second", dt[13:15])
+// sortKeyLayout - time stamps output by -sortkey, which sort as text in the same order as in time
+// they are always in UTC so that daylight saving time changes can not reorder them
+const sortKeyLayout string = "20060102150405"
 
-	return time.Date(year, time.Month(month), day, hour, minute, second, 0, time.Now().Location())
+// logfmtValue - quote a logfmt value when it is empty or contains spaces, quotes, or equal signs
+func logfmtValue(v string) string {
+	if len(v) == 0 || strings.ContainsAny(v, " \t\"=") {
+		return strconv.Quote(v)
+	}
+	return v
 }
 
-// setFileTime - update a timestamps for a group of files
-// op should equal: (a)ccess, (m)odify, (b)oth
-func setFileTime(args []string, dt, op string) {
+// logfmtLine - return a record as key=value pairs on a single line
+func logfmtLine(rec fileRecord) string {
+	pairs := []string{"name=" + logfmtValue(rec.Name)}
+	if len(rec.Host) > 0 {
+		pairs = append(pairs, "hostname="+logfmtValue(rec.Host))
+	}
+	if len(rec.RealPath) > 0 {
+		pairs = append(pairs, "realpath="+logfmtValue(rec.RealPath))
+	}
+	if !opts.noSize {
+		pairs = append(pairs, "size="+strconv.FormatInt(rec.Size, 10))
+	}
+	if len(rec.Type) > 0 {
+		pairs = append(pairs, "type="+logfmtValue(rec.Type))
+	}
+	if rec.Btime != nil {
+		pairs = append(pairs, birthLabel+"="+rec.Btime.Format(time.RFC3339Nano))
+	}
+	if rec.Ctime != nil {
+		pairs = append(pairs, changeLabel+"="+rec.Ctime.Format(time.RFC3339Nano))
+	}
+	pairs = append(pairs, "mtime="+rec.Mtime.Format(time.RFC3339Nano))
+	if !rec.Atime.IsZero() {
+		pairs = append(pairs, "atime="+rec.Atime.Format(time.RFC3339Nano))
+	}
+	if len(rec.Precision) > 0 {
+		pairs = append(pairs, "precision="+logfmtValue(rec.Precision))
+	}
+	if len(rec.Skew) > 0 {
+		pairs = append(pairs, "am_skew="+logfmtValue(rec.Skew))
+	}
+	return strings.Join(pairs, " ")
+}
+
+// writeRecords - output the records collected by displayRecord in the selected format
+// followed by any -footer; with -stale or -assert-unchanged, exit with an error when any files were listed
+func writeRecords() {
+	failed := false
+	if opts.summaryJSON {
+		writeSummaryJSON()
+	} else if opts.json {
+		writeJSON()
+	} else if opts.table {
+		writeTable()
+	} else if opts.csv {
+		writeCSV()
+	} else if opts.prometheus {
+		writePrometheus()
+	} else if opts.byDay {
+		writeByDay()
+	} else if opts.byExt {
+		writeByExt()
+	} else if opts.groupByDir {
+		writeGroupedByDir()
+	} else if opts.tree {
+		writeTree()
+	} else if opts.findDupes {
+		writeDupes()
+	} else if len(opts.dupesDigest) > 0 {
+		writeContentDupes()
+	} else if len(opts.uniq) > 0 {
+		writeUniq()
+	} else if opts.relNewest {
+		writeRelNewest()
+	} else if opts.histogram {
+		writeHistogram()
+	} else if opts.ageDays {
+		writeAgeDays()
+	} else if opts.reportCapabilities {
+		writeCapabilityReport()
+	} else if opts.stale > 0 {
+		failed = writeStale() > 0
+	} else if opts.snapshot != nil {
+		failed = writeSnapshotDiffs() > 0
+	}
+	endReport()
+	flushBatch()
+	if failed {
+		os.Exit(1)
+	}
+}
+
+// writeJSON - output records in compact or indented JSON format
+// with -max-results, the records are wrapped in the same object as a wildcard /stat response so truncation can be flagged
+func writeJSON() {
+	var v any = records
+	if opts.maxResults > 0 {
+		v = globResponse{Files: records, Truncated: truncated}
+	}
+	var out []byte
 	var err error
+	if opts.jsonPretty {
+		out, err = json.MarshalIndent(v, "", "  ")
+	} else {
+		out, err = json.Marshal(v)
+	}
+	if err != nil {
+		log.Fatalf("JSON Error: %s\n", err)
+	}
+	fmt.Fprintln(output, string(out))
+}
+
+// writeTable - output records as aligned columns, one file per row
+// the BTIME and CTIME columns are only included when at least one file has them
+func writeTable() {
+	hasBirth, hasChange := false, false
+	for _, rec := range records {
+		hasBirth = hasBirth || rec.Btime != nil
+		hasChange = hasChange || rec.Ctime != nil
+	}
+	optional := func(t *time.Time) string {
+		if t == nil {
+			return "-"
+		}
+		return formatTime(*t)
+	}
+
+	w := tabwriter.NewWriter(output, 0, 0, 2, ' ', 0)
+	header := []string{"NAME"}
+	if !opts.noSize {
+		header = append(header, "SIZE")
+	}
+	if hasBirth {
+		header = append(header, strings.ToUpper(birthLabel))
+	}
+	if hasChange {
+		header = append(header, strings.ToUpper(changeLabel))
+	}
+	header = append(header, "MTIME", "ATIME")
+	fmt.Fprintln(w, strings.Join(header, "\t"))
+
+	for _, rec := range records {
+		row := []string{rec.Name}
+		if !opts.noSize {
+			row = append(row, Format(rec.Size))
+		}
+		if hasBirth {
+			row = append(row, optional(rec.Btime))
+		}
+		if hasChange {
+			row = append(row, optional(rec.Ctime))
+		}
+		atime := "-"
+		if !rec.Atime.IsZero() {
+			atime = formatTime(rec.Atime)
+		}
+		row = append(row, formatTime(rec.Mtime), atime)
+		fmt.Fprintln(w, strings.Join(row, "\t"))
+	}
+	w.Flush()
+}
+
+// startTime - when gostat started, or the time given to -now, used for "now" so that every file and field is given the same time
+// the monotonic clock reading is stripped so it is not displayed
+var startTime = currentClock.Now().Round(0)
+
+// dateFormatHelp - the time stamp formats accepted by createDate, as shown to the user
+const dateFormatHelp string = "YYYYMMDD.HHMMSS, RFC3339, @EPOCH, @FILE, @ANCHOR, or now"
+
+// dateLayouts - time stamp formats accepted by createDate, tried in order
+var dateLayouts = []string{
+	"20060102.150405",
+	time.RFC3339,
+}
+
+// createDate - return a time.Time value when given a string in one of the dateLayouts formats,
+// "now", an anchor such as @som, @N for N seconds (or -epoch-unit) since the Unix epoch, or a partial YYYYMMDD.HHMMSS
+func createDate(dt string) (time.Time, error) {
+	if "now" == dt {
+		return startTime, nil
+	}
+	if fixed, found := replaceLeapSecond(dt); found {
+		if !opts.lenient {
+			return time.Time{}, fmt.Errorf("%s is a leap second, which file time stamps can not represent; use 59 seconds or add -lenient", dt)
+		}
+		t, err := createDate(fixed)
+		if err != nil {
+			return t, err
+		}
+		// the last instant before the following minute
+		return t.Add(time.Second - time.Nanosecond), nil
+	}
+	if strings.HasPrefix(dt, "@") {
+		if t, found := resolveAnchor(dt[1:]); found {
+			return t, nil
+		}
+		if t, err := parseEpoch(dt[1:], opts.epochUnit); err == nil {
+			return t, nil
+		}
+	}
+	for _, layout := range dateLayouts {
+		t, err := time.ParseInLocation(layout, dt, time.Local)
+		if err == nil {
+			return t, nil
+		}
+	}
+	return parsePartialDate(dt)
+}
 
-	for _, file := range expandGlobs(args) {
-		currentTimes := getFileTimes(file)
-		if "m" == op {
-			err = os.Chtimes(file, currentTimes["a"], createDate(dt))
-		} else if "a" == op {
-			fmt.Println(createDate(dt))
-			err = os.Chtimes(file, createDate(dt), currentTimes["m"])
-		} else if "b" == op {
-			dateTime := createDate(dt)
-			err = os.Chtimes(file, dateTime, dateTime)
-		} else {
-			log.Fatalf("Invalid op: %s\n", op)
+// opFields - the time stamps changed by each op: (a)ccess, (m)odify, (b)oth
+var opFields = map[string][]string{
+	"a": {"a"},
+	"m": {"m"},
+	"b": {"a", "m"},
+}
+
+// fieldNames - the display names of the time stamps returned by getFileTimes
+var fieldNames = map[string]string{
+	"a": "atime",
+	"m": "mtime",
+	"b": birthLabel,
+	"c": changeLabel,
+}
+
+// fieldByName - return the getFileTimes key for a field given as a letter or as its display name
+func fieldByName(name string) (string, bool) {
+	for field, display := range fieldNames {
+		if name == field || name == display {
+			return field, true
+		}
+	}
+	return "", false
+}
+
+// comparedFields - return the fields given to -fields, or defaults when it was not given
+func comparedFields(defaults []string) []string {
+	if opts.compareFields != nil {
+		return opts.compareFields
+	}
+	return defaults
+}
+
+// describeTimes - return a summary of the time stamps about to be set, such as: mtime to 2021-03-29 ...
+func describeTimes(newTimes map[string]time.Time) string {
+	var changes []string
+	for _, field := range []string{"a", "m"} {
+		if t, found := newTimes[field]; found {
+			changes = append(changes, fmt.Sprintf("%s to %s", fieldNames[field], formatTime(t)))
+		}
+	}
+	return strings.Join(changes, ", ")
+}
+
+// resolveTime - return the time given to -a, -m, or -b for the field being set
+// a trailing offset, as in now-2h or 20250101.000000+30m, is added to the time stamp before it
+func resolveTime(value, field string) (time.Time, error) {
+	base, offset, found, offsetErr := splitOffset(value)
+	if found {
+		if t, err := resolveBaseTime(base, field); err == nil {
+			return t.Add(offset), nil
+		}
+	}
+	t, err := resolveBaseTime(value, field)
+	if err != nil && offsetErr != nil {
+		return t, offsetErr
+	}
+	return t, err
+}
+
+// resolveBaseTime - return the time for a single time stamp, without any offset
+// @FILE, when it is not an epoch, copies that same field from another file
+func resolveBaseTime(value, field string) (time.Time, error) {
+	t, err := createDate(value)
+	if err == nil || !strings.HasPrefix(value, "@") {
+		return t, err
+	}
+	rec, err := statFile(value[1:])
+	if err != nil {
+		return time.Time{}, fmt.Errorf("not an anchor, epoch, or readable reference file: %w", err)
+	}
+	return rec.times()[field], nil
+}
+
+// reportInterrupted - output how far processing got before SIGINT was received
+func reportInterrupted(processed, total int) {
+	fmt.Fprintf(os.Stderr, "interrupted: processed %d of %d files\n", processed, total)
+}
+
+// timeResolver - return the times to set for a file, keyed "a" and/or "m"
+// a missing key keeps that time stamp as is, and a nil map with a nil error skips the file
+type timeResolver func(rec fileRecord) (map[string]time.Time, error)
+
+// fixedTimes - return a timeResolver which gives every file the same times
+func fixedTimes(newTimes map[string]time.Time) timeResolver {
+	return func(fileRecord) (map[string]time.Time, error) {
+		return newTimes, nil
+	}
+}
+
+// offsetTimes - return a timeResolver which moves each file's access and modify times by offset
+// with opts.sameWeekday, each time is then moved to the nearest day on the same weekday it was on before
+func offsetTimes(offset time.Duration) timeResolver {
+	return func(rec fileRecord) (map[string]time.Time, error) {
+		atime, mtime := rec.Atime.Add(offset), rec.Mtime.Add(offset)
+		if opts.sameWeekday {
+			atime, mtime = sameWeekday(rec.Atime, atime), sameWeekday(rec.Mtime, mtime)
+		}
+		return map[string]time.Time{"a": atime, "m": mtime}, nil
+	}
+}
+
+// futureTimes - a timeResolver which sets access and modify times that are in the future to now
+// files with neither time in the future are skipped
+func futureTimes(rec fileRecord) (map[string]time.Time, error) {
+	newTimes := make(map[string]time.Time)
+	for field, t := range map[string]time.Time{"a": rec.Atime, "m": rec.Mtime} {
+		if t.After(startTime) {
+			newTimes[field] = startTime
+		}
+	}
+	if len(newTimes) == 0 {
+		return nil, nil
+	}
+	return newTimes, nil
+}
+
+// chtimes - set a file's access and modify times, waiting for -min-interval and retrying as -retry allows
+func chtimes(file string, atime, mtime time.Time) error {
+	return withRetry(file, func() error {
+		throttle()
+		return os.Chtimes(file, atime, mtime)
+	})
+}
+
+// setFileTime - update a timestamps for a group of files, using resolve to decide each file's new times
+// when opts.confirm is set, the user is prompted before each file is changed
+// no new files are started once ctx is cancelled
+func setFileTime(ctx context.Context, allFiles []string, resolve timeResolver) {
+	var stdin *bufio.Reader
+	if opts.confirm {
+		stdin = bufio.NewReader(os.Stdin)
+	}
+
+	for i, file := range allFiles {
+		if ctx.Err() != nil {
+			reportInterrupted(i, len(allFiles))
+			break
+		}
+		if opts.batchSize > 0 && i > 0 && i%opts.batchSize == 0 {
+			endBatch(i, len(allFiles))
+		}
+		rec, err := statFile(file)
+		if err != nil {
+			logError("Lstat Error: %s\n", err)
+			continue
+		}
+		if !selected(rec) {
+			continue
+		}
+		if age := fileAge(rec.Mtime); opts.protectNewer > 0 && age < opts.protectNewer {
+			logError("Protected: %s was modified %s ago, skipping\n", file, age.Round(time.Second))
+			continue
+		}
+		newTimes, err := resolve(rec)
+		if err != nil {
+			logError("Error: %s: %s\n", file, err)
+			continue
+		}
+		if newTimes == nil {
+			continue
+		}
+		if opts.update {
+			if newTimes = newerTimes(rec, newTimes); newTimes == nil {
+				if opts.verbose {
+					log.Printf("Note: %s already has times at least as new, skipping\n", file)
+				}
+				continue
+			}
+		}
+		if opts.showCommand {
+			for _, cmd := range touchCommands(file, newTimes) {
+				fmt.Fprintln(output, cmd)
+			}
+		}
+		if opts.dryRun {
+			if !opts.showCommand {
+				fmt.Fprintf(output, "would set %s of %s\n", describeTimes(newTimes), file)
+			}
+			continue
+		}
+		if stdin != nil {
+			question := fmt.Sprintf("set %s of %s?", describeTimes(newTimes), file)
+			switch confirm(stdin, os.Stderr, question) {
+			case answerNo:
+				continue
+			case answerAll:
+				stdin = nil
+			}
 		}
+		currentTimes := rec.times()
+		atime, mtime := currentTimes["a"], currentTimes["m"]
+		if t, found := newTimes["a"]; found {
+			atime = t
+		}
+		if t, found := newTimes["m"]; found {
+			mtime = t
+		}
+		if err = chtimes(file, atime, mtime); err != nil {
+			logError("os.Chtimes Error: %s\n", err.Error())
+			if createdFiles[file] {
+				// do not leave behind a file with the wrong time stamps
+				if err = os.Remove(file); err != nil {
+					logError("Remove Error: %s\n", err)
+				}
+			}
+			continue
+		}
+		if opts.touchDirs {
+			noteDirMtime(file, mtime)
+		}
+		if err = writeAudit(file, currentTimes, newTimes); err != nil {
+			logError("Audit Error: %s\n", err)
+		}
+		rec, err = statFile(file)
 		if err != nil {
-			log.Printf("os.Chtimes Error: %s\n", err.Error())
+			logError("Lstat Error: %s\n", err)
 			continue
 		}
-		showFileTimes([]string{file})
+		displayRecord(rec, currentTimes)
+		if opts.verify {
+			verifyTimes(rec, newTimes)
+		}
+		if opts.exec != nil {
+			runExec(opts.exec, file)
+		}
+	}
+	if opts.touchDirs {
+		touchContainingDirs()
+	}
+}
+
+// newerTimes - return only those of newTimes which are later than the file's current times, for -update
+// returns nil when none are
+func newerTimes(rec fileRecord, newTimes map[string]time.Time) map[string]time.Time {
+	current := rec.times()
+	newer := make(map[string]time.Time)
+	for field, t := range newTimes {
+		if t.After(current[field]) {
+			newer[field] = t
+		}
+	}
+	if len(newer) == 0 {
+		return nil
+	}
+	return newer
+}
+
+// verifyFailures - number of files whose times did not match what was set
+var verifyFailures int
+
+// timesEqual - return true when two times are no more than opts.tolerance apart
+// all comparisons between expected and actual times should use this
+func timesEqual(a, b time.Time) bool {
+	diff := a.Sub(b)
+	if diff < 0 {
+		diff = -diff
+	}
+	return diff <= opts.tolerance
+}
+
+// verifyTimes - confirm that a file's times, as read back after being set, equal newTimes within opts.tolerance
+// file systems such as FAT silently round time stamps, so the stored value is reported when it differs
+func verifyTimes(rec fileRecord, newTimes map[string]time.Time) bool {
+	ok := true
+	current := rec.times()
+	for _, field := range comparedFields([]string{"a", "m"}) {
+		want, found := newTimes[field]
+		if !found {
+			continue
+		}
+		if got := current[field]; !timesEqual(got, want) {
+			logError("Verify Error: %s: %s was set to %s but is stored as %s\n", rec.Name, fieldNames[field], want, got)
+			ok = false
+		}
+	}
+	if !ok {
+		verifyFailures += 1
+	}
+	return ok
+}
+
+// subcommands - the operations which can be given before any options
+var subcommands = []struct {
+	name string
+	desc string
+}{
+	{"show", "display file times, this is the default"},
+	{"set", "change file times with one of: " + strings.Join(setModeFlags, ", ")},
+}
+
+// setModeFlags - the options which choose new times, only one of them can be given
+var setModeFlags = []string{"-a", "-m", "-b", "-from-metadata", "-offset", "-offset-from", "-fix-future", "-from-csv", "-from-content", "-from-name", "-relative-to", "-git-date"}
+
+// takeSubcommand - remove a subcommand from the start of args and return it, or return an empty string
+func takeSubcommand(args []string) (string, []string) {
+	if len(args) == 0 {
+		return "", args
+	}
+	for _, sub := range subcommands {
+		if args[0] == sub.name {
+			return sub.name, args[1:]
+		}
 	}
+	return "", args
 }
 
 func showUsage() {
-	fmt.Fprintf(os.Stderr, "Usage: %s [OPTION]... [FILE]...\n", pgmName)
+	fmt.Fprintf(os.Stderr, "Usage: %s [COMMAND] [OPTION]... [FILE]...\n", pgmName)
 	fmt.Fprintf(os.Stderr, "%s\n\n", pgmDesc)
+	fmt.Fprintf(os.Stderr, "Commands:\n")
+	for _, sub := range subcommands {
+		fmt.Fprintf(os.Stderr, "  %-6s %s\n", sub.name, sub.desc)
+	}
+	fmt.Fprintf(os.Stderr, "\nOptions:\n")
 	flag.PrintDefaults()
 }
 
@@ -184,23 +1391,359 @@ func showVersion() {
 
 func main() {
 	argsVersion := flag.Bool("v", false, "show program version and then exit")
-	argsAccess := flag.String("a", "", "set file access time, format: YYYYMMDD.HHMMSS")
-	argsModify := flag.String("m", "", "set file modify time, format: YYYYMMDD.HHMMSS")
-	argsBoth := flag.String("b", "", "set both access and modify time, format: YYYYMMDD.HHMMSS")
+	argsOutput := flag.String("o", "", "write output to this file instead of STDOUT")
+	argsCapabilities := flag.String("capabilities", "", "show which time stamps are available for a file and then exit")
+	argsArchive := flag.String("archive", "", "show the times stored for each entry of this zip, tar, or gzipped tar file and then exit")
+	argsServe := flag.String("serve", "", "answer HTTP requests for /stat?path=FILE with JSON times on this address, such as :8080 for localhost only or unix:PATH")
+	argsCheckDate := flag.String("check-date", "", "parse a time stamp, show the result and then exit")
+	argsAccess := flag.String("a", "", "set file access time, format: "+dateFormatHelp)
+	argsModify := flag.String("m", "", "set file modify time, format: "+dateFormatHelp)
+	argsBoth := flag.String("b", "", "set both access and modify time, format: "+dateFormatHelp)
+	flag.IntVar(&opts.limit, "limit", 0, "only display the first N matched files, 0 for no limit")
+	flag.IntVar(&opts.maxResults, "max-results", 0, "stop after N files and report that more matched, with -json, -jsonl, and -serve as \"truncated\": true; 0 for no limit")
+	flag.BoolVar(&opts.quietErrors, "quiet-errors", false, "do not output non-fatal errors and warnings, such as for missing files")
+	flag.BoolVar(&opts.verbose, "verbose", false, "output additional details to STDERR")
+	flag.BoolVar(&opts.create, "create", false, "create files that do not exist, times are set to now unless -a, -m, or -b is also given")
+	flag.BoolVar(&opts.confirm, "i", false, "prompt before changing the times of each file")
+	flag.BoolVar(&opts.xattr, "xattr", false, "show the names and sizes of extended attributes")
+	flag.BoolVar(&opts.json, "json", false, "output in compact JSON format")
+	flag.BoolVar(&opts.jsonPretty, "json-pretty", false, "output in indented JSON format")
+	flag.BoolVar(&opts.jsonl, "jsonl", false, "output one line of compact JSON per file as soon as it is read, even with -batch-size")
+	flag.BoolVar(&opts.prometheus, "prometheus", false, "output file times and sizes as metrics in the Prometheus text format")
+	flag.BoolVar(&opts.csv, "csv", false, "output the path, modify and access time of each file as CSV, which -from-csv can read back")
+	argsFromContent := flag.String("from-content", "", "set each file's modify time to the date on its first line, read with this Go time layout such as \"2006-01-02 15:04:05\"")
+	argsFromName := flag.String("from-name", "", "set each file's modify time to a date in its name found by this regular expression, using its first capture group if it has one")
+	argsNameLayout := flag.String("name-layout", "20060102", "Go time layout of the date found by -from-name")
+	argsFromCSV := flag.String("from-csv", "", "set the times of each file listed in this CSV file, which has path, mtime, and atime columns")
+	flag.BoolVar(&opts.table, "table", false, "output one row per file with aligned columns")
+	argsMinSize := flag.String("min-size", "", "only include files of at least this size, such as 10K, 1.5M, or 2GB")
+	argsMaxSize := flag.String("max-size", "", "only include files of at most this size, such as 10K, 1.5M, or 2GB")
+	argsEmpty := flag.Bool("empty", false, "only include zero byte files")
+	flag.BoolVar(&opts.precision, "precision", false, "show the apparent resolution of each file's modify time")
+	flag.BoolVar(&opts.stdin, "stdin", false, "read additional file names from STDIN, one per line")
+	flag.IntVar(&opts.stdinField, "stdin-field", 0, "use only this column of each STDIN line as the file name, starting at 1; implies -stdin")
+	flag.StringVar(&opts.stdinDelim, "stdin-delim", "\t", "column delimiter used by -stdin-field")
+	flag.BoolVar(&opts.verify, "verify", false, "after setting times, read them back and report any that were not stored as given")
+	flag.BoolVar(&opts.tree, "tree", false, "output files as an indented tree with their modify times, most useful with -R")
+	flag.BoolVar(&opts.byExt, "by-ext", false, "instead of each file, show the number of files and total size for each file name extension")
+	flag.BoolVar(&opts.byDay, "by-day", false, "instead of each file, show the number of files and total size modified on each day")
+	flag.BoolVar(&opts.symlinkDetail, "symlink-detail", false, "for symbolic links, also show the link's own times")
+	flag.BoolVar(&opts.naturalSort, "natural-sort", false, "process files in numeric aware order, so file2 comes before file10")
+	flag.DurationVar(&opts.tolerance, "tolerance", 0, "treat times this close together as equal when verifying, such as 2s for FAT")
+	flag.DurationVar(&opts.stale, "stale", 0, "only list files not modified within this duration, such as 1h, and exit with an error if there are any")
+	flag.BoolVar(&opts.recursive, "R", false, "include everything beneath matched directories")
+	flag.IntVar(&opts.maxDepth, "max-depth", -1, "with -R or a ** wildcard, descend at most this many directories below where the walk starts, -1 for no limit")
+	flag.BoolVar(&opts.dirsOnly, "dirs-only", false, "with -R, only include directories")
+	flag.BoolVar(&opts.filesOnly, "files-only", false, "with -R, only include entries which are not directories")
+	flag.BoolVar(&opts.fromMetadata, "from-metadata", false, "set each file's modify time to the date stored inside of it, such as a JPEG's EXIF DateTimeOriginal")
+	flag.BoolVar(&opts.showCommand, "show-command", false, "output the equivalent touch command, or PowerShell on Windows, before setting each file's times")
+	flag.BoolVar(&opts.dryRun, "dry-run", false, "show what would be set without changing any files")
+	flag.BoolVar(&opts.update, "update", false, "only change a time when the new one is later than the file's current one, as with rsync --update")
+	flag.DurationVar(&opts.minInterval, "min-interval", 0, "wait at least this long between reading or changing each file's times, such as 50ms, to go easy on shared storage")
+	flag.IntVar(&opts.retry, "retry", 0, "retry reading or setting a file's times up to N times, waiting longer each time, for unreliable network file systems")
+	flag.BoolVar(&opts.validateAll, "validate-all", false, "before changing any times, check that every file exists and is writable, and change nothing if any are not")
+	flag.BoolVar(&opts.explain, "explain", false, "describe which files would be matched and what would be done to them, then exit without doing it")
+	flag.BoolVar(&opts.findDupes, "find-dupes", false, "instead of each file, show groups of files with the same modify time")
+	flag.StringVar(&opts.dupesDigest, "dupes-by-content", "", "instead of each file, show groups of files with identical contents, compared by this hash: "+strings.Join(digestNames(), ", "))
+	flag.BoolVar(&opts.dupesSize, "dupes-size", false, "files must also be the same size for -find-dupes, implies -find-dupes")
+	flag.BoolVar(&opts.showType, "show-type", false, "show the kind of each file, such as regular file, directory, or symlink")
+	flag.StringVar(&opts.types, "type", "", "only include these kinds of files, combine letters for more than one: (f)ile, (d)irectory, (l)ink, (p)ipe, (s)ocket, (c)haracter and (b)lock device")
+	flag.BoolVar(&opts.logfmt, "logfmt", false, "output one line of key=value pairs per file")
+	flag.DurationVar(&opts.protectNewer, "protect-newer", 0, "do not change the times of files modified within this duration, such as 1h")
+	flag.StringVar(&opts.header, "header", "", "output this line before the files, {count}, {total}, and {date} are replaced by the number and total size of the files and the current time")
+	flag.StringVar(&opts.footer, "footer", "", "output this line after the files, with the same replacements as -header")
+	flag.BoolVar(&opts.amSkew, "am-skew", false, "show the access time minus the modify time of each file")
+	flag.BoolVar(&opts.touchDirs, "touch-containing-dir", false, "after changing files, also set the modify time of each containing directory to the newest one set within it")
+	flag.StringVar(&opts.format, "format", os.Getenv("GOSTAT_FORMAT"), "display times as: "+strings.Join(timeFormats, ", ")+" (env: GOSTAT_FORMAT)")
+	flag.BoolVar(&opts.lenient, "lenient", false, "accept a leap second of 60 in time stamps, using 59.999999999 instead")
+	flag.StringVar(&opts.epochUnit, "epoch-unit", "auto", "unit of @EPOCH time stamps: s, ms, us, ns, or auto to guess from the number of digits")
+	flag.StringVar(&opts.locale, "locale", "", "display times as is customary in this locale, such as en-US or en-GB; -format takes precedence")
+	argsNow := flag.String("now", "", "use this time instead of the current time for now, anchors, partial time stamps, and file ages, format: "+dateFormatHelp)
+	flag.StringVar(&opts.tz, "tz", os.Getenv("GOSTAT_TZ"), "display and parse times in this time zone, such as UTC or America/New_York (env: GOSTAT_TZ)")
+	flag.BoolVar(&opts.canonicalTimes, "canonical-times", false, "display times truncated to whole seconds, for output that can be compared across runs and file systems")
+	flag.BoolVar(&opts.rfc3339, "rfc3339", false, "display times in RFC3339 format with nanoseconds")
+	flag.BoolVar(&opts.realpath, "realpath", false, "show the absolute path of each file with symbolic links resolved")
+	flag.BoolVar(&opts.groupByDir, "group-by-dir", false, "output files grouped under a heading for each directory")
+	flag.BoolVar(&opts.noSize, "no-size", false, "do not show file sizes")
+	flag.BoolVar(&opts.summaryJSON, "summary-json", false, "instead of each file, output a JSON object with the count, total and average size, and oldest and newest modify times")
+	flag.DurationVar(&opts.timeout, "timeout", 0, "skip a file when reading its times takes longer than this duration, such as 5s")
+	argsOffset := flag.Duration("offset", 0, "move the access and modify times of each file by this duration, such as -2h or 30m")
+	argsOffsetFrom := flag.String("offset-from", "", "move the access and modify times of each file back by the age of this file's modify time")
+	flag.BoolVar(&opts.sameWeekday, "same-weekday", false, "with -offset or -offset-from, move each time to the nearest day on the same weekday as before")
+	flag.IntVar(&opts.jobs, "j", 1, "stat up to this many files at once when displaying times")
+	flag.BoolVar(&opts.reportCapabilities, "report-capabilities", false, "instead of each file, show how many files had each time stamp available")
+	flag.BoolVar(&opts.ageDays, "age-days", false, "instead of each file, output how many days ago it was modified as a decimal number, followed by its name")
+	flag.BoolVar(&opts.histogram, "histogram", false, "output a bar chart of how many files were modified within the last hour, day, week, month, and before that")
+	flag.BoolVar(&opts.relNewest, "rel-newest", false, "show each modify time as an offset from the newest one among the matched files")
+	argsCreatedAfter := flag.String("created-after", "", "only include files with a "+birthLabel+" after this time, format: "+dateFormatHelp)
+	argsCreatedBefore := flag.String("created-before", "", "only include files with a "+birthLabel+" before this time, format: "+dateFormatHelp)
+	flag.BoolVar(&opts.inclusive, "inclusive", false, "with -created-after, -created-before, or -stale, also include files exactly at the boundary")
+	flag.BoolVar(&opts.createdMissing, "created-missing", false, "with -created-after or -created-before, include files whose "+birthLabel+" is unavailable instead of skipping them")
+	flag.Var(&opts.roots, "root", "match relative file names and wildcards beneath this directory, can be given more than once")
+	argsUser := flag.String("user", "", "only include files owned by this user name or id")
+	argsGroup := flag.String("group", "", "only include files belonging to this group name or id")
+	argsHostname := flag.Bool("hostname", false, "include the name of this computer with each file, to tell apart output merged from several hosts")
+	argsSortKey := flag.String("sortkey", "", "output this time stamp of each file as UTC digits, YYYYMMDDHHMMSS, followed by its name, so that sort orders files by time: atime, mtime, "+birthLabel+", or "+changeLabel)
+	argsUniq := flag.String("uniq", "", "output one file for each distinct value of this time stamp, along with how many files share it: atime, mtime, "+birthLabel+", or "+changeLabel)
+	argsFields := flag.String("fields", "", "with -verify or -assert-unchanged, compare only these time stamps, separated by commas, such as m,a")
+	argsAssertUnchanged := flag.String("assert-unchanged", "", "compare files to this snapshot saved with -json, list any whose modify or "+birthLabel+" differ and exit with an error")
+	argsIgnoreFile := flag.String("ignore-file", "", "skip files matching the glob patterns in this file, one per line in the style of .gitignore")
+	flag.IntVar(&opts.batchSize, "batch-size", 0, "when setting times, buffer output and write it every N files, with -verbose also showing progress")
+	flag.BoolVar(&opts.force, "force", false, "allow changing times in system directories such as /etc or C:\\Windows")
+	argsFixFuture := flag.Bool("fix-future", false, "set access and modify times which are in the future to now, other files are left alone")
+	argsAppendLog := flag.String("append-log", "", "append a line to this file recording each change that is made")
+	argsFromCmd := flag.String("from-cmd", "", "use the output of this command as the time stamp for -a, -m, or -b when given the value: cmd")
+	argsGitDate := flag.Bool("git-date", false, "set each file's modify time to the date of the latest commit of the git repository it is in")
+	argsGitRepo := flag.String("git-repo", "", "with -git-date, use the latest commit of the git repository in this directory for every file")
+	argsRelativeTo := flag.String("relative-to", "", "set each file's modify time to that of this marker file plus an optional offset, such as deploy+10m; a bare name is looked for in $"+anchorDirEnv)
+	argsPreserveAround := flag.String("preserve-around", "", "run this command, then restore the access and modify times it changed on matched files")
+	argsExec := flag.String("exec", "", "run a command for each file after displaying its times, {} is replaced with the file name")
 	flag.Usage = showUsage
-	flag.Parse()
+	subcommand, rest := takeSubcommand(os.Args[1:])
+	flag.CommandLine.Parse(rest)
 
 	if *argsVersion {
 		showVersion()
 		os.Exit(0)
 	}
 
+	if len(opts.tz) > 0 {
+		loc, err := time.LoadLocation(opts.tz)
+		if err != nil {
+			log.Fatalf("Error: -tz: %s\n", err)
+		}
+		// file times are read, time stamps parsed, and everything displayed in this time zone
+		time.Local = loc
+		startTime = startTime.In(loc)
+	}
+	if len(*argsNow) > 0 {
+		// parsed after -tz so that it is read in that time zone, and before anything else uses now
+		t, err := createDate(*argsNow)
+		if err != nil {
+			log.Fatalf("Error: -now: %s\n", err)
+		}
+		setClock(fixedClock{t: t})
+	}
+	if opts.rfc3339 {
+		opts.format = "rfc3339"
+	}
+	if _, found := epochUnits[opts.epochUnit]; !found && opts.epochUnit != "auto" {
+		log.Fatalf("Error: -epoch-unit must be one of: s, ms, us, ns, auto\n")
+	}
+
+	if len(*argsOutput) > 0 {
+		f, err := os.Create(*argsOutput)
+		if err != nil {
+			log.Fatalf("Error: %s\n", err)
+		}
+		output = f
+	}
+
+	if len(*argsCapabilities) > 0 {
+		if err := showCapabilities(*argsCapabilities); err != nil {
+			log.Fatalf("Error: %s\n", err)
+		}
+		os.Exit(0)
+	}
+
+	if len(*argsCheckDate) > 0 {
+		dateTime, err := resolveTime(*argsCheckDate, "m")
+		if err != nil {
+			log.Fatalf("Error: %s\nPlease use: %s\n", err, dateFormatHelp)
+		}
+		fmt.Fprintln(output, formatTime(dateTime))
+		os.Exit(0)
+	}
+
+	if opts.jsonPretty {
+		opts.json = true
+	}
+	if opts.jsonl && (opts.json || opts.collect()) {
+		log.Fatalf("Error: -jsonl can not be combined with other output formats\n")
+	}
+
+	var err error
+	if (opts.dirsOnly || opts.filesOnly) && !opts.recursive {
+		log.Fatalf("Error: -dirs-only and -files-only require -R\n")
+	}
+	if opts.dirsOnly && opts.filesOnly {
+		log.Fatalf("Error: -dirs-only and -files-only are mutually exclusive\n")
+	}
+	if opts.dupesSize {
+		opts.findDupes = true
+	}
+	for _, r := range opts.types {
+		if !strings.ContainsRune(fileTypeLetters, r) {
+			log.Fatalf("Error: invalid -type: %c, use one or more of: %s\n", r, fileTypeLetters)
+		}
+	}
+	if opts.tolerance < 0 {
+		log.Fatalf("Error: -tolerance can not be negative\n")
+	}
+	if _, found := localeLayout(opts.locale); len(opts.locale) > 0 && !found {
+		log.Fatalf("Error: -locale must be one of: %s\n", strings.Join(localeNames(), ", "))
+	}
+	if len(opts.format) > 0 && !slices.Contains(timeFormats, opts.format) {
+		log.Fatalf("Error: -format or GOSTAT_FORMAT must be one of: %s\n", strings.Join(timeFormats, ", "))
+	}
+	createdBounds := []struct {
+		name  string
+		value string
+		bound *time.Time
+	}{
+		{"created-after", *argsCreatedAfter, &opts.createdAfter},
+		{"created-before", *argsCreatedBefore, &opts.createdBefore},
+	}
+	for _, c := range createdBounds {
+		if len(c.value) == 0 {
+			continue
+		}
+		if *c.bound, err = resolveTime(c.value, "b"); err != nil {
+			log.Fatalf("Error: -%s: %s\nPlease use: %s\n", c.name, err, dateFormatHelp)
+		}
+		if c.bound.IsZero() {
+			log.Fatalf("Error: -%s: %s is unavailable for %s\n", c.name, birthLabel, c.value)
+		}
+	}
+	if len(*argsUser) > 0 {
+		if opts.uid, err = lookupUser(*argsUser); err != nil {
+			log.Fatalf("Error: -user: %s\n", err)
+		}
+	}
+	if len(*argsGroup) > 0 {
+		if opts.gid, err = lookupGroup(*argsGroup); err != nil {
+			log.Fatalf("Error: -group: %s\n", err)
+		}
+	}
+	if *argsHostname {
+		if opts.hostname, err = os.Hostname(); err != nil {
+			log.Fatalf("Error: -hostname: %s\n", err)
+		}
+	}
+	if opts.batchSize < 0 {
+		log.Fatalf("Error: -batch-size can not be negative\n")
+	}
+	if len(opts.dupesDigest) > 0 {
+		if err := validDigest(opts.dupesDigest); err != nil {
+			log.Fatalf("Error: -dupes-by-content: %s\n", err)
+		}
+	}
+	if opts.maxDepth < -1 {
+		log.Fatalf("Error: -max-depth can not be less than -1\n")
+	}
+	if opts.retry < 0 {
+		log.Fatalf("Error: -retry must be at least 0\n")
+	}
+	if opts.jobs < 1 {
+		log.Fatalf("Error: -j must be at least 1\n")
+	}
+	if len(*argsUniq) > 0 {
+		field, ok := fieldByName(*argsUniq)
+		if !ok {
+			log.Fatalf("Error: -uniq must be one of: atime, mtime, %s, %s\n", birthLabel, changeLabel)
+		}
+		opts.uniq = field
+	}
+	if len(*argsSortKey) > 0 {
+		field, ok := fieldByName(*argsSortKey)
+		if !ok {
+			log.Fatalf("Error: -sortkey must be one of: atime, mtime, %s, %s\n", birthLabel, changeLabel)
+		}
+		opts.sortKey = field
+		if opts.jsonl || opts.collect() {
+			log.Fatalf("Error: -sortkey can not be combined with other output formats\n")
+		}
+	}
+	if len(*argsFields) > 0 {
+		for _, name := range strings.Split(*argsFields, ",") {
+			field, ok := fieldByName(strings.TrimSpace(name))
+			if !ok {
+				log.Fatalf("Error: -fields must be a list of: a, m, b, c or atime, mtime, %s, %s\n", birthLabel, changeLabel)
+			}
+			if !slices.Contains(opts.compareFields, field) {
+				opts.compareFields = append(opts.compareFields, field)
+			}
+		}
+	}
+	if len(*argsAssertUnchanged) > 0 {
+		if opts.snapshot, err = loadSnapshot(*argsAssertUnchanged); err != nil {
+			log.Fatalf("Error: -assert-unchanged: %s\n", err)
+		}
+	}
+	if len(*argsIgnoreFile) > 0 {
+		if opts.ignore, err = readIgnoreFile(*argsIgnoreFile); err != nil {
+			log.Fatalf("Error: -ignore-file: %s\n", err)
+		}
+	}
+	if len(*argsMinSize) > 0 {
+		if opts.minSize, err = parseSize(*argsMinSize); err != nil {
+			log.Fatalf("Error: -min-size: %s\n", err)
+		}
+	}
+	if len(*argsMaxSize) > 0 {
+		if opts.maxSize, err = parseSize(*argsMaxSize); err != nil {
+			log.Fatalf("Error: -max-size: %s\n", err)
+		}
+	}
+	if *argsEmpty {
+		opts.maxSize = 0
+	}
+
+	if opts.stdinField > 0 {
+		opts.stdin = true
+	}
+
+	if len(*argsServe) > 0 {
+		if err := serve(*argsServe); err != nil {
+			log.Fatalf("Error: -serve: %s\n", err)
+		}
+		os.Exit(0)
+	}
+
+	if len(*argsArchive) > 0 {
+		beginReport()
+		if err := showArchive(*argsArchive); err != nil {
+			log.Fatalf("Error: -archive: %s\n", err)
+		}
+		writeRecords()
+		os.Exit(0)
+	}
+
 	args := flag.Args()
-	if 0 == len(args) {
+	if 0 == len(args) && !opts.stdin && len(*argsFromCSV) == 0 {
 		showUsage()
 		os.Exit(1)
 	}
 
+	if slices.Contains(args, stdinName) && (opts.stdin || opts.confirm) {
+		log.Fatalf("Error: %s can not be used with -stdin or -i\n", stdinName)
+	}
+
+	var stdinFiles []string
+	if opts.stdin {
+		if opts.confirm {
+			log.Fatalf("Error: -i can not be used with -stdin\n")
+		}
+		if stdinFiles, err = readPaths(os.Stdin, opts.stdinField, opts.stdinDelim); err != nil {
+			log.Fatalf("STDIN Error: %s\n", err)
+		}
+	}
+
+	if len(*argsExec) > 0 {
+		cmdArgs, err := splitCommand(*argsExec)
+		if err != nil {
+			log.Fatalf("Error: %s\n", err)
+		}
+		opts.exec = cmdArgs
+	}
+
+	// flag.Visit only reports flags given on the command line, so -m "" can be told apart from no -m at all
+	flag.Visit(func(f *flag.Flag) {
+		if _, ok := opFields[f.Name]; ok && len(strings.TrimSpace(f.Value.String())) == 0 {
+			log.Fatalf("Error: -%s requires a time stamp, format: %s\n", f.Name, dateFormatHelp)
+		}
+	})
+
 	wantChange := 0
 	op := ""
 	newTime := ""
@@ -223,17 +1766,227 @@ func main() {
 		log.Fatalf("-a, -m, and -b are all mutually exclusive\n")
 	}
 
+	if len(*argsFromCmd) > 0 && newTime != "cmd" {
+		log.Fatalf("Error: -from-cmd requires one of -a, -m, or -b to be given the value: cmd\n")
+	}
+	if len(*argsFromCmd) == 0 && newTime == "cmd" {
+		log.Fatalf("Error: the value cmd requires -from-cmd\n")
+	}
+
+	newTimes := make(map[string]time.Time)
+	if newTime == "cmd" {
+		dateTime, err := timeFromCommand(*argsFromCmd)
+		if err != nil {
+			log.Fatalf("Error: %s\nPlease use: %s\n", err, dateFormatHelp)
+		}
+		for _, field := range opFields[op] {
+			newTimes[field] = dateTime
+		}
+	} else if wantChange > 0 {
+		for _, field := range opFields[op] {
+			dateTime, err := resolveTime(newTime, field)
+			if err != nil {
+				log.Fatalf("Error: %s\nPlease use: %s\n", err, dateFormatHelp)
+			}
+			newTimes[field] = dateTime
+		}
+	}
+
+	// only one way of choosing the new times can be given
+	// plan describes the change for -explain
+	var resolve timeResolver
+	var plan string
+	setModes := 0
 	if wantChange > 0 {
-		validDT := regexp.MustCompile(`20\d{2}\d{2}\d{2}.\d{2}\d{2}\d{2}$`)
-		if validDT.MatchString(newTime) == false {
-			log.Fatalf("Error: invalid time stamp: %s\nPlease use: YYYYMMDD.HHMMSS\n", newTime)
+		setModes += 1
+		resolve = fixedTimes(newTimes)
+		plan = "set " + describeTimes(newTimes)
+	}
+	if opts.fromMetadata {
+		setModes += 1
+		resolve = metadataTimes
+		plan = "set each modify time to the date stored inside of the file"
+	}
+	if *argsOffset != 0 {
+		setModes += 1
+		resolve = offsetTimes(*argsOffset)
+		plan = fmt.Sprintf("move each access and modify time by %s", *argsOffset)
+	}
+	if len(*argsOffsetFrom) > 0 {
+		setModes += 1
+		ref, err := statFile(*argsOffsetFrom)
+		if err != nil {
+			log.Fatalf("Error: -offset-from: %s\n", err)
+		}
+		// make each file as much older as the reference file is
+		resolve = offsetTimes(-fileAge(ref.Mtime))
+		plan = fmt.Sprintf("move each access and modify time back by %s, the age of %s", fileAge(ref.Mtime).Round(time.Second), *argsOffsetFrom)
+	}
+	if *argsFixFuture {
+		setModes += 1
+		resolve = futureTimes
+		plan = "set access and modify times which are in the future to " + formatTime(startTime)
+	}
+	if len(*argsFromContent) > 0 {
+		setModes += 1
+		resolve = contentTimes(*argsFromContent)
+		plan = fmt.Sprintf("set each modify time to the date on the file's first line, read as %q", *argsFromContent)
+	}
+	if len(*argsFromName) > 0 {
+		setModes += 1
+		re, err := regexp.Compile(*argsFromName)
+		if err != nil {
+			log.Fatalf("Error: -from-name: %s\n", err)
+		}
+		resolve = nameTimes(re, *argsNameLayout)
+		plan = fmt.Sprintf("set each modify time to the date in the file's name matching %q, read as %q", *argsFromName, *argsNameLayout)
+	}
+	if *argsGitDate {
+		setModes += 1
+		resolve = gitTimes(*argsGitRepo)
+		plan = "set each modify time to the date of the latest git commit"
+		if len(*argsGitRepo) > 0 {
+			plan += " in " + *argsGitRepo
+		}
+	} else if len(*argsGitRepo) > 0 {
+		log.Fatalf("Error: -git-repo requires -git-date\n")
+	}
+	if len(*argsRelativeTo) > 0 {
+		setModes += 1
+		t, marker, err := relativeTime(*argsRelativeTo)
+		if err != nil {
+			log.Fatalf("Error: -relative-to: %s\n", err)
+		}
+		resolve = fixedTimes(map[string]time.Time{"m": t})
+		plan = fmt.Sprintf("set mtime to %s, relative to %s", formatTime(t), marker)
+	}
+	var csvFiles []string
+	if len(*argsFromCSV) > 0 {
+		setModes += 1
+		var byFile map[string]map[string]time.Time
+		if csvFiles, byFile, err = readCSVTimes(*argsFromCSV); err != nil {
+			log.Fatalf("Error: -from-csv: %s\n", err)
+		}
+		resolve = csvTimes(byFile)
+		plan = fmt.Sprintf("set the times of %d file(s) listed in %s", len(csvFiles), *argsFromCSV)
+	}
+	if opts.sameWeekday {
+		if *argsOffset == 0 && len(*argsOffsetFrom) == 0 {
+			log.Fatalf("Error: -same-weekday requires -offset or -offset-from\n")
+		}
+		plan += ", then to the nearest day on the same weekday"
+	}
+	if setModes > 1 {
+		log.Fatalf("Error: only one of these can be given: %s\n", strings.Join(setModeFlags, ", "))
+	}
+	switch {
+	case subcommand == "show" && resolve != nil:
+		log.Fatalf("Error: show does not change file times, use: %s set\n", pgmName)
+	case subcommand == "set" && resolve == nil:
+		log.Fatalf("Error: set requires one of: %s\n", strings.Join(setModeFlags, ", "))
+	case subcommand == "" && resolve != nil && opts.verbose:
+		log.Printf("Note: changing times without the set command is deprecated, use: %s set\n", pgmName)
+	}
+
+	if len(*argsPreserveAround) > 0 && resolve != nil {
+		log.Fatalf("Error: -preserve-around can not be combined with: %s\n", strings.Join(setModeFlags, ", "))
+	}
+	if resolve != nil && slices.Contains(args, stdinName) {
+		log.Fatalf("Error: the times of %s can be displayed but not set\n", stdinName)
+	}
+	if opts.confirm && resolve != nil && !stdinIsTerminal() {
+		log.Fatalf("Error: -i requires STDIN to be a terminal\n")
+	}
+
+	if len(*argsAppendLog) > 0 && !opts.dryRun && !opts.explain {
+		if err := openAuditLog(*argsAppendLog); err != nil {
+			log.Fatalf("Error: %s\n", err)
 		}
-		setFileTime(args, newTime, op)
+	}
+
+	if opts.create && !opts.dryRun && !opts.explain {
+		createMissing(args)
+		createMissing(stdinFiles)
+	}
+	// file names read from STDIN or -from-csv are used as is, without wildcard expansion
+	allFiles := append(expandGlobs(args), stdinFiles...)
+	allFiles = append(allFiles, csvFiles...)
+	if opts.recursive {
+		allFiles = expandRecursive(allFiles)
+	}
+	if opts.naturalSort {
+		sort.SliceStable(allFiles, func(i, j int) bool {
+			return naturalLess(allFiles[i], allFiles[j])
+		})
+	}
+
+	if opts.explain {
+		writeExplanation(args, allFiles, plan)
 		os.Exit(0)
 	}
+	if len(*argsPreserveAround) > 0 {
+		exitCode, err := preserveAround(allFiles, *argsPreserveAround)
+		writeRecords()
+		if err != nil {
+			log.Fatalf("Error: -preserve-around: %s\n", err)
+		}
+		os.Exit(exitCode)
+	}
+
+	// finish the current file on the first SIGINT, a second one exits immediately
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	go func() {
+		<-ctx.Done()
+		stop()
+	}()
 
-	count := showFileTimes(args)
+	if opts.batchSize > 0 {
+		startBatches()
+	}
+	beginReport()
+	if resolve != nil && !opts.force && !opts.dryRun {
+		if file, dir, count := refuseProtected(allFiles); count > 0 {
+			log.Fatalf("Error: refusing to change %d file(s) in protected system directories, such as %s in %s; use -force to allow this\n", count, file, dir)
+		}
+	}
+	if resolve != nil && opts.validateAll {
+		if problems := validateTargets(args, allFiles); len(problems) > 0 {
+			for _, problem := range problems {
+				log.Printf("Invalid: %s\n", problem)
+			}
+			log.Fatalf("Error: %d file(s) can not be changed, no times were changed\n", len(problems))
+		}
+	}
+	if resolve != nil {
+		setFileTime(ctx, allFiles, resolve)
+		writeRecords()
+		exitOnInterrupt(ctx)
+		exitOnExecFailures()
+		if verifyFailures > 0 {
+			log.Fatalf("Error: %d file(s) failed verification\n", verifyFailures)
+		}
+		os.Exit(0)
+	}
+
+	count := showFileTimes(ctx, allFiles)
+	writeRecords()
+	exitOnInterrupt(ctx)
 	if count == 0 {
 		log.Fatalf("Error: %s did not match any files\n", args)
 	}
+	exitOnExecFailures()
+}
+
+// exitOnInterrupt - exit with exitInterrupted when processing was stopped by SIGINT
+func exitOnInterrupt(ctx context.Context) {
+	if ctx.Err() != nil {
+		os.Exit(exitInterrupted)
+	}
+}
+
+// exitOnExecFailures - exit with an error when any -exec command failed
+func exitOnExecFailures() {
+	if execFailures > 0 {
+		log.Fatalf("Error: %d -exec command(s) failed\n", execFailures)
+	}
 }
@@ -0,0 +1,1178 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"os/user"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// defaultOpts - the options before any flags are parsed, restored by resetState
+var defaultOpts = opts
+
+// TestMain - run main instead of the tests when started by runGostat
+func TestMain(m *testing.M) {
+	if os.Getenv("GOSTAT_TEST_MAIN") == "1" {
+		main()
+		os.Exit(0)
+	}
+	os.Exit(m.Run())
+}
+
+// runGostat - run the program with args and return its output, errors, and exit code
+func runGostat(t *testing.T, args ...string) (string, string, int) {
+	t.Helper()
+	return runGostatWith(t, nil, "", args...)
+}
+
+// runGostatWith - run the program as runGostat does, with env added to its environment and input as its STDIN
+func runGostatWith(t *testing.T, env []string, input string, args ...string) (string, string, int) {
+	t.Helper()
+	cmd := exec.Command(os.Args[0], args...)
+	cmd.Env = append(append(os.Environ(), env...), "GOSTAT_TEST_MAIN=1")
+	cmd.Stdin = strings.NewReader(input)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	err := cmd.Run()
+	code := 0
+	if exitErr, ok := err.(*exec.ExitError); ok {
+		code = exitErr.ExitCode()
+	} else if err != nil {
+		t.Fatalf("running %v: %s", args, err)
+	}
+	return stdout.String(), stderr.String(), code
+}
+
+// resetState - give a test the default options and collect its output, restoring everything afterwards
+func resetState(t *testing.T) *bytes.Buffer {
+	t.Helper()
+	savedOpts, savedOutput, savedRecords, savedStart, savedClock := opts, output, records, startTime, currentClock
+	t.Cleanup(func() {
+		opts, output, records, startTime, currentClock = savedOpts, savedOutput, savedRecords, savedStart, savedClock
+	})
+	buf := &bytes.Buffer{}
+	opts = defaultOpts
+	output = buf
+	records = []fileRecord{}
+	return buf
+}
+
+// writeFile - create file with the given contents, access and modify time, and return its name
+func writeFile(t *testing.T, file, contents string, mtime time.Time) string {
+	t.Helper()
+	if err := os.WriteFile(file, []byte(contents), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chtimes(file, mtime, mtime); err != nil {
+		t.Fatal(err)
+	}
+	return file
+}
+
+// tempFile - create a file in a new temporary directory with the given contents and modify time
+func tempFile(t *testing.T, name, contents string, mtime time.Time) string {
+	t.Helper()
+	return writeFile(t, filepath.Join(t.TempDir(), name), contents, mtime)
+}
+
+// shownNames - return the file names in text output, in order
+func shownNames(out string) []string {
+	var names []string
+	for _, line := range strings.Split(out, "\n") {
+		if name, found := strings.CutPrefix(line, "name  : "); found {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// outputLine - return the line of text output starting with prefix, such as "mtime :"
+func outputLine(out, prefix string) string {
+	for _, line := range strings.Split(out, "\n") {
+		if strings.HasPrefix(line, prefix) {
+			return line
+		}
+	}
+	return ""
+}
+
+func TestAnnotate(t *testing.T) {
+	t1 := time.Date(2021, 3, 29, 9, 8, 7, 0, time.UTC)
+	t2 := t1.Add(time.Second)
+	prev := map[string]time.Time{"m": t1}
+	tests := []struct {
+		prev  map[string]time.Time
+		field string
+		t     time.Time
+		want  string
+	}{
+		{nil, "m", t1, ""},
+		{prev, "m", t1, " (unchanged)"},
+		{prev, "m", t2, " (changed)"},
+		{prev, "a", t1, " (changed)"},
+	}
+	for _, tt := range tests {
+		if got := annotate(tt.prev, tt.field, tt.t); got != tt.want {
+			t.Errorf("annotate(%v, %q, %s) = %q, want %q", tt.prev, tt.field, tt.t, got, tt.want)
+		}
+	}
+}
+
+func TestSetMarkers(t *testing.T) {
+	old := time.Date(2020, 1, 1, 0, 0, 0, 0, time.Local)
+	tests := []struct {
+		op           string
+		atime, mtime string
+	}{
+		{"-a", "(changed)", "(unchanged)"},
+		{"-m", "(unchanged)", "(changed)"},
+		{"-b", "(changed)", "(changed)"},
+	}
+	for _, tt := range tests {
+		file := tempFile(t, "f.txt", "x", old)
+		out, stderr, code := runGostat(t, "set", tt.op, "20210329.090807", file)
+		if code != 0 {
+			t.Fatalf("%s: exit code %d: %s", tt.op, code, stderr)
+		}
+		if line := outputLine(out, "atime :"); !strings.HasSuffix(line, tt.atime) {
+			t.Errorf("%s: atime line %q, want %s", tt.op, line, tt.atime)
+		}
+		if line := outputLine(out, "mtime :"); !strings.HasSuffix(line, tt.mtime) {
+			t.Errorf("%s: mtime line %q, want %s", tt.op, line, tt.mtime)
+		}
+	}
+}
+
+// tempFiles - create count empty files in a new temporary directory and return their paths
+func tempFiles(t *testing.T, count int) []string {
+	t.Helper()
+	dir := t.TempDir()
+	var files []string
+	for i := 0; i < count; i++ {
+		file := filepath.Join(dir, fmt.Sprintf("f%d.txt", i))
+		if err := os.WriteFile(file, nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+		files = append(files, file)
+	}
+	return files
+}
+
+func TestLimit(t *testing.T) {
+	files := tempFiles(t, 5)
+	out, stderr, code := runGostat(t, append([]string{"-limit", "2", "-verbose"}, files...)...)
+	if code != 0 {
+		t.Fatalf("exit code %d: %s", code, stderr)
+	}
+	if n := strings.Count(out, "name  :"); n != 2 {
+		t.Errorf("displayed %d files, want 2", n)
+	}
+	if !strings.Contains(stderr, "displayed 2 of 5 matched files") {
+		t.Errorf("-verbose did not report the total: %q", stderr)
+	}
+}
+
+func TestMaxResults(t *testing.T) {
+	files := tempFiles(t, 3)
+
+	out, stderr, code := runGostat(t, append([]string{"-max-results", "2", "-jsonl"}, files...)...)
+	if code != 0 {
+		t.Fatalf("-jsonl exit code %d: %s", code, stderr)
+	}
+	lines := strings.Split(strings.TrimSpace(out), "\n")
+	if len(lines) != 3 || lines[2] != `{"truncated":true}` {
+		t.Errorf("-max-results 2 -jsonl gave %q, want two records and a truncated line", out)
+	}
+
+	var resp globResponse
+	out, stderr, code = runGostat(t, append([]string{"-max-results", "2", "-json"}, files...)...)
+	if err := json.Unmarshal([]byte(out), &resp); err != nil || code != 0 {
+		t.Fatalf("-json gave %q, %v, exit code %d: %s", out, err, code, stderr)
+	}
+	if len(resp.Files) != 2 || resp.Files[0].Name != files[0] || resp.Files[1].Name != files[1] || !resp.Truncated {
+		t.Errorf("-max-results 2 -json gave %+v, want the first two files and truncated", resp)
+	}
+
+	resp = globResponse{}
+	out, _, _ = runGostat(t, append([]string{"-max-results", "3", "-json"}, files...)...)
+	if err := json.Unmarshal([]byte(out), &resp); err != nil || len(resp.Files) != 3 || resp.Truncated {
+		t.Errorf("-max-results 3 -json of three files gave %q, %v, want every file and not truncated", out, err)
+	}
+
+	out, stderr, _ = runGostat(t, append([]string{"-max-results", "2"}, files...)...)
+	if n := strings.Count(out, "name  :"); n != 2 || !strings.Contains(stderr, "stopped after 2 results") {
+		t.Errorf("-max-results 2 displayed %d files with %q, want 2 and a note", n, stderr)
+	}
+}
+
+func TestCheckDate(t *testing.T) {
+	out, stderr, code := runGostat(t, "-tz", "UTC", "-check-date", "20210329.090807")
+	if code != 0 {
+		t.Fatalf("exit code %d: %s", code, stderr)
+	}
+	if want := "2021-03-29 09:08:07 +0000 UTC\n"; out != want {
+		t.Errorf("-check-date output %q, want %q", out, want)
+	}
+
+	_, stderr, code = runGostat(t, "-check-date", "bogus")
+	if code == 0 {
+		t.Errorf("an invalid time stamp exited with 0")
+	}
+	if !strings.Contains(stderr, "invalid time stamp: bogus") {
+		t.Errorf("unexpected error for an invalid time stamp: %q", stderr)
+	}
+}
+
+func TestCreateDate(t *testing.T) {
+	resetState(t)
+	opts.epochUnit = "auto"
+	startTime = time.Date(2025, 6, 15, 13, 14, 15, 0, time.Local)
+	tests := []struct {
+		dt   string
+		want time.Time
+	}{
+		{"now", startTime},
+		{"20210329.090807", time.Date(2021, 3, 29, 9, 8, 7, 0, time.Local)},
+		{"2021-03-29T09:08:07Z", time.Date(2021, 3, 29, 9, 8, 7, 0, time.UTC)},
+		{"2021-03-29T09:08:07-05:00", time.Date(2021, 3, 29, 14, 8, 7, 0, time.UTC)},
+		{"@1617023287", time.Unix(1617023287, 0)},
+		{"1230", time.Date(2025, 6, 15, 12, 30, 0, 0, time.Local)},
+	}
+	for _, tt := range tests {
+		got, err := createDate(tt.dt)
+		if err != nil {
+			t.Errorf("createDate(%q): %s", tt.dt, err)
+			continue
+		}
+		if !got.Equal(tt.want) {
+			t.Errorf("createDate(%q) = %s, want %s", tt.dt, got, tt.want)
+		}
+	}
+
+	for _, bad := range []string{"", "yesterday", "2021-03-29", "20210329.256000"} {
+		if _, err := createDate(bad); err == nil {
+			t.Errorf("createDate(%q) did not fail", bad)
+		}
+	}
+}
+
+func TestCreateThenSet(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "new.txt")
+	_, stderr, code := runGostat(t, "set", "-create", "-m", "20210329.090807", file)
+	if code != 0 {
+		t.Fatalf("exit code %d: %s", code, stderr)
+	}
+	info, err := os.Stat(file)
+	if err != nil {
+		t.Fatalf("-create did not make the file: %s", err)
+	}
+	if want := time.Date(2021, 3, 29, 9, 8, 7, 0, time.Local); !info.ModTime().Equal(want) {
+		t.Errorf("modify time is %s, want %s", info.ModTime(), want)
+	}
+}
+
+func TestCreateThenFailedSetRemovesFile(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("symbolic links need extra privileges on Windows")
+	}
+	resetState(t)
+	file := filepath.Join(t.TempDir(), "new.txt")
+	createMissing([]string{file})
+	t.Cleanup(func() { delete(createdFiles, file) })
+	if !createdFiles[file] {
+		t.Fatalf("createMissing did not record %s", file)
+	}
+
+	// once the file has been read, replace it with a dangling symbolic link so that setting its times fails
+	resolve := func(rec fileRecord) (map[string]time.Time, error) {
+		if err := os.Remove(file); err != nil {
+			return nil, err
+		}
+		if err := os.Symlink(filepath.Join(filepath.Dir(file), "missing"), file); err != nil {
+			return nil, err
+		}
+		return map[string]time.Time{"m": time.Date(2021, 3, 29, 9, 8, 7, 0, time.Local)}, nil
+	}
+	opts.quietErrors = true
+	setFileTime(context.Background(), []string{file}, resolve)
+	if _, err := os.Lstat(file); !os.IsNotExist(err) {
+		t.Errorf("the created file was not removed after its times could not be set: %v", err)
+	}
+}
+
+func TestWriteJSON(t *testing.T) {
+	buf := resetState(t)
+	mtime := time.Date(2021, 3, 29, 9, 8, 7, 0, time.UTC)
+	records = []fileRecord{{Name: "a.txt", Size: 1, Mtime: mtime, Atime: mtime}, {Name: "b.txt", Size: 2, Mtime: mtime, Atime: mtime}}
+
+	writeJSON()
+	compact := strings.TrimSuffix(buf.String(), "\n")
+	if strings.Contains(compact, "\n") || strings.Contains(compact, "  ") {
+		t.Errorf("compact JSON has newlines or indentation: %q", compact)
+	}
+
+	buf.Reset()
+	opts.jsonPretty = true
+	writeJSON()
+	pretty := buf.String()
+	if !strings.Contains(pretty, "\n  {\n    \"name\": \"a.txt\",") {
+		t.Errorf("pretty JSON is not indented: %q", pretty)
+	}
+}
+
+func TestOutputFile(t *testing.T) {
+	mtime := time.Date(2021, 3, 29, 9, 8, 7, 0, time.UTC)
+	file := tempFile(t, "a.txt", "abc", mtime)
+	dest := filepath.Join(t.TempDir(), "out.json")
+
+	stdout, stderr, code := runGostat(t, "-json", "-o", dest, file)
+	if code != 0 || len(stdout) > 0 {
+		t.Fatalf("-o exited %d with output %q, errors %q", code, stdout, stderr)
+	}
+	data, err := os.ReadFile(dest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []fileRecord
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatalf("%s is not JSON: %s: %q", dest, err, data)
+	}
+	if len(got) != 1 || got[0].Name != file || got[0].Size != 3 || !got[0].Mtime.Equal(mtime) {
+		t.Errorf("read back %+v, want %s with size 3 and mtime %s", got, file, mtime)
+	}
+}
+
+func TestStatTimeout(t *testing.T) {
+	resetState(t)
+	saved := readRecord
+	t.Cleanup(func() { readRecord = saved })
+	release := make(chan struct{})
+	t.Cleanup(func() { close(release) })
+	readRecord = func(file string) (fileRecord, error) {
+		if file == "slow" {
+			<-release
+		}
+		return fileRecord{Name: file}, nil
+	}
+
+	opts.timeout = 20 * time.Millisecond
+	if _, err := statFile("slow"); !errors.Is(err, errTimedOut) {
+		t.Errorf("a hung stat returned %v, want errTimedOut", err)
+	}
+	rec, err := statFile("fast")
+	if err != nil || rec.Name != "fast" {
+		t.Errorf("a quick stat returned %v, %v", rec, err)
+	}
+}
+
+func TestWriteTable(t *testing.T) {
+	buf := resetState(t)
+	opts.format = "rfc3339"
+	mtime := time.Date(2021, 3, 29, 9, 8, 7, 0, time.UTC)
+	btime := mtime.Add(-time.Hour)
+	records = []fileRecord{
+		{Name: "a.txt", Size: 1, Mtime: mtime, Atime: mtime},
+		{Name: "longer-name.txt", Size: 12345, Btime: &btime, Mtime: mtime, Atime: mtime},
+	}
+	writeTable()
+
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("got %d lines, want a header and 2 rows: %q", len(lines), lines)
+	}
+	header := strings.Fields(lines[0])
+	want := []string{"NAME", "SIZE", strings.ToUpper(birthLabel), "MTIME", "ATIME"}
+	if strings.Join(header, " ") != strings.Join(want, " ") {
+		t.Errorf("header is %q, want %q", header, want)
+	}
+	if row := strings.Fields(lines[1]); row[2] != "-" {
+		t.Errorf("a file without %s shows %q instead of -", birthLabel, row[2])
+	}
+	// every column starts at the same position on each line
+	for _, column := range []string{"SIZE", "MTIME", "ATIME"} {
+		pos := strings.Index(lines[0], column)
+		for _, line := range lines[1:] {
+			if pos == 0 || line[pos-1] != ' ' || line[pos] == ' ' {
+				t.Errorf("column %s is not aligned in %q", column, line)
+			}
+		}
+	}
+}
+
+func TestCopyTimeFromFile(t *testing.T) {
+	refTime := time.Date(2019, 7, 4, 12, 0, 0, 123456789, time.UTC)
+	ref := tempFile(t, "ref.txt", "", refTime)
+	file := tempFile(t, "target.txt", "", time.Now())
+	if _, stderr, code := runGostat(t, "set", "-m", "@"+ref, file); code != 0 {
+		t.Fatalf("exit code %d: %s", code, stderr)
+	}
+	info, err := os.Stat(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !info.ModTime().Equal(refTime) {
+		t.Errorf("modify time is %s, want the reference file's %s", info.ModTime(), refTime)
+	}
+}
+
+// captureStderr - return what fn writes to os.Stderr
+func captureStderr(t *testing.T, fn func()) string {
+	t.Helper()
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	saved := os.Stderr
+	os.Stderr = w
+	defer func() { os.Stderr = saved }()
+	done := make(chan string)
+	go func() {
+		data, _ := io.ReadAll(r)
+		done <- string(data)
+	}()
+	fn()
+	w.Close()
+	return <-done
+}
+
+func TestInterrupted(t *testing.T) {
+	buf := resetState(t)
+	files := tempFiles(t, 3)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	stderr := captureStderr(t, func() { showFileTimes(ctx, files) })
+	if !strings.Contains(stderr, "interrupted: processed 0 of 3 files") || buf.Len() != 0 {
+		t.Errorf("a cancelled show displayed %q and reported %q", buf.String(), stderr)
+	}
+
+	// the file being changed when the interrupt arrives is finished, and no others are started
+	ctx, cancel = context.WithCancel(context.Background())
+	defer cancel()
+	resolve := func(rec fileRecord) (map[string]time.Time, error) {
+		cancel()
+		return map[string]time.Time{"m": time.Date(2021, 3, 29, 9, 8, 7, 0, time.Local)}, nil
+	}
+	stderr = captureStderr(t, func() { setFileTime(ctx, files, resolve) })
+	if !strings.Contains(stderr, "interrupted: processed 1 of 3 files") {
+		t.Errorf("a cancelled set reported %q", stderr)
+	}
+	if names := shownNames(buf.String()); len(names) != 1 || names[0] != files[0] {
+		t.Errorf("a cancelled set changed %v, want only %s", names, files[0])
+	}
+}
+
+func TestTimePrecision(t *testing.T) {
+	tests := []struct {
+		ns   int
+		want string
+	}{
+		{0, "seconds"},
+		{500000000, "milliseconds"},
+		{123000000, "milliseconds"},
+		{123456000, "microseconds"},
+		{123456700, "100 nanoseconds"},
+		{123456789, "nanoseconds"},
+	}
+	for _, tt := range tests {
+		if got := timePrecision(time.Date(2021, 3, 29, 9, 8, 7, tt.ns, time.UTC)); got != tt.want {
+			t.Errorf("timePrecision with %d ns = %q, want %q", tt.ns, got, tt.want)
+		}
+	}
+}
+
+func TestVerifyTimes(t *testing.T) {
+	resetState(t)
+	opts.quietErrors = true
+	t.Cleanup(func() { verifyFailures = 0 })
+	want := time.Date(2021, 3, 29, 9, 8, 7, 0, time.Local)
+	file := tempFile(t, "v.txt", "", want)
+	rec, err := statFile(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !verifyTimes(rec, map[string]time.Time{"a": want, "m": want}) || verifyFailures != 0 {
+		t.Errorf("verifyTimes failed for times which were stored as set")
+	}
+	if verifyTimes(rec, map[string]time.Time{"m": want.Add(time.Hour)}) || verifyFailures != 1 {
+		t.Errorf("verifyTimes passed for a different modify time")
+	}
+}
+
+func TestShowCapabilities(t *testing.T) {
+	buf := resetState(t)
+	file := tempFile(t, "c.txt", "", time.Now())
+	if err := showCapabilities(file); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"access : yes, can be set", "modify : yes, can be set", "birth  : ", "change : "} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("capabilities %q do not include %q", buf.String(), want)
+		}
+	}
+	if err := showCapabilities(file + ".missing"); err == nil {
+		t.Errorf("a missing file did not fail")
+	}
+}
+
+func TestNewLinkRecord(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("symbolic links need extra privileges on Windows")
+	}
+	targetTime := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	target := tempFile(t, "target.txt", "", targetTime)
+	link := filepath.Join(filepath.Dir(target), "link")
+	if err := os.Symlink(target, link); err != nil {
+		t.Fatal(err)
+	}
+
+	rec, err := newLinkRecord(link)
+	if err != nil || rec == nil {
+		t.Fatalf("newLinkRecord = %v, %v", rec, err)
+	}
+	if rec.Target != target {
+		t.Errorf("link target is %q, want %q", rec.Target, target)
+	}
+	// the link was made just now, long after the time given to its target
+	if rec.Mtime.Equal(targetTime) || rec.Mtime.Before(targetTime) {
+		t.Errorf("the link's own modify time %s is the target's %s", rec.Mtime, targetTime)
+	}
+
+	if rec, err := newLinkRecord(target); rec != nil || err != nil {
+		t.Errorf("a regular file gave a link record %v, %v", rec, err)
+	}
+}
+
+func TestTimesEqual(t *testing.T) {
+	resetState(t)
+	base := time.Date(2021, 3, 29, 9, 8, 7, 0, time.UTC)
+	later := base.Add(1500 * time.Millisecond)
+
+	opts.tolerance = 2 * time.Second
+	if !timesEqual(base, later) || !timesEqual(later, base) {
+		t.Errorf("times 1.5s apart differ with a 2s tolerance")
+	}
+	opts.tolerance = time.Second
+	if timesEqual(base, later) || timesEqual(later, base) {
+		t.Errorf("times 1.5s apart are equal with a 1s tolerance")
+	}
+	opts.tolerance = 0
+	if !timesEqual(base, base.In(time.FixedZone("EST", -5*3600))) {
+		t.Errorf("the same instant in another zone differs")
+	}
+}
+
+func TestExpandTilde(t *testing.T) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		t.Skip("no home directory:", err)
+	}
+	tests := []struct {
+		path, want string
+	}{
+		{"~", home},
+		{"~/notes.txt", filepath.Join(home, "notes.txt")},
+		{"notes~.txt", "notes~.txt"},
+		{"/tmp/~", "/tmp/~"},
+	}
+	for _, tt := range tests {
+		got, err := expandTilde(tt.path)
+		if err != nil || got != tt.want {
+			t.Errorf("expandTilde(%q) = %q, %v, want %q", tt.path, got, err, tt.want)
+		}
+	}
+
+	if u, err := user.Current(); err == nil && len(u.Username) > 0 && len(u.HomeDir) > 0 {
+		got, err := expandTilde("~" + u.Username + "/x")
+		if err != nil || got != filepath.Join(u.HomeDir, "x") {
+			t.Errorf("expandTilde(~%s/x) = %q, %v, want %q", u.Username, got, err, filepath.Join(u.HomeDir, "x"))
+		}
+	}
+	if _, err := expandTilde("~no-such-user-gostat/x"); err == nil {
+		t.Errorf("an unknown user was expanded")
+	}
+}
+
+func TestFileType(t *testing.T) {
+	dir := t.TempDir()
+	file := writeFile(t, filepath.Join(dir, "a.txt"), "", time.Now())
+	paths := map[string]string{file: "regular file", dir: "directory"}
+	if mkfifo, err := exec.LookPath("mkfifo"); err == nil {
+		fifo := filepath.Join(dir, "fifo")
+		if err := exec.Command(mkfifo, fifo).Run(); err != nil {
+			t.Fatal(err)
+		}
+		paths[fifo] = "fifo"
+	}
+	for path, want := range paths {
+		fi, err := os.Lstat(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := fileType(fi.Mode()); got != want {
+			t.Errorf("fileType(%s) = %q, want %q", path, got, want)
+		}
+	}
+
+	modes := map[os.FileMode]string{
+		os.ModeSymlink:                    "symlink",
+		os.ModeNamedPipe:                  "fifo",
+		os.ModeSocket:                     "socket",
+		os.ModeDevice | os.ModeCharDevice: "character device",
+		os.ModeDevice:                     "block device",
+		os.ModeIrregular:                  "irregular file",
+	}
+	for mode, want := range modes {
+		if got := fileType(mode); got != want {
+			t.Errorf("fileType(%s) = %q, want %q", mode, got, want)
+		}
+	}
+}
+
+// parseLogfmt - split a logfmt line into its keys and values, unquoting quoted values
+func parseLogfmt(t *testing.T, line string) map[string]string {
+	t.Helper()
+	pairs := make(map[string]string)
+	for len(line) > 0 {
+		eq := strings.IndexByte(line, '=')
+		if eq <= 0 {
+			t.Fatalf("no key in %q", line)
+		}
+		key, rest := line[:eq], line[eq+1:]
+		var value string
+		if strings.HasPrefix(rest, `"`) {
+			quoted, err := strconv.QuotedPrefix(rest)
+			if err != nil {
+				t.Fatalf("bad quoting in %q: %s", rest, err)
+			}
+			value, _ = strconv.Unquote(quoted)
+			rest = rest[len(quoted):]
+		} else if end := strings.IndexByte(rest, ' '); end >= 0 {
+			value, rest = rest[:end], rest[end:]
+		} else {
+			value, rest = rest, ""
+		}
+		pairs[key] = value
+		line = strings.TrimPrefix(rest, " ")
+	}
+	return pairs
+}
+
+func TestLogfmtLine(t *testing.T) {
+	resetState(t)
+	mtime := time.Date(2021, 3, 29, 9, 8, 7, 123, time.UTC)
+	atime := mtime.Add(time.Hour)
+	rec := fileRecord{Name: `my "a=b" file.txt`, Size: 42, Type: "regular file", Mtime: mtime, Atime: atime}
+
+	got := parseLogfmt(t, logfmtLine(rec))
+	want := map[string]string{
+		"name":  rec.Name,
+		"size":  "42",
+		"type":  "regular file",
+		"mtime": "2021-03-29T09:08:07.000000123Z",
+		"atime": "2021-03-29T10:08:07.000000123Z",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("logfmtLine parsed back to %q, want %q", got, want)
+	}
+	if _, err := time.Parse(time.RFC3339Nano, got["mtime"]); err != nil {
+		t.Errorf("mtime does not parse: %s", err)
+	}
+}
+
+func TestProtectNewer(t *testing.T) {
+	dir := t.TempDir()
+	fresh := writeFile(t, filepath.Join(dir, "fresh.txt"), "", time.Now())
+	oldTime := time.Now().Add(-48 * time.Hour).Truncate(time.Second)
+	old := writeFile(t, filepath.Join(dir, "old.txt"), "", oldTime)
+	want := time.Date(2021, 3, 29, 9, 8, 7, 0, time.UTC)
+
+	_, stderr, code := runGostat(t, "-protect-newer", "1h", "-m", want.Format(time.RFC3339), fresh, old)
+	if code != 0 {
+		t.Fatalf("-protect-newer exited %d: %s", code, stderr)
+	}
+	if !strings.Contains(stderr, "Protected: "+fresh+" was modified") {
+		t.Errorf("the fresh file was not reported as protected: %q", stderr)
+	}
+	if rec, err := newFileRecord(fresh); err != nil || rec.Mtime.Equal(want) {
+		t.Errorf("the fresh file was changed to %s, %v", rec.Mtime, err)
+	}
+	if rec, err := newFileRecord(old); err != nil || !rec.Mtime.Equal(want) {
+		t.Errorf("the old file has %s, %v, want %s", rec.Mtime, err, want)
+	}
+}
+
+func TestNewerTimes(t *testing.T) {
+	current := time.Date(2021, 3, 29, 9, 8, 7, 0, time.UTC)
+	rec := fileRecord{Name: "a.txt", Mtime: current, Atime: current}
+	later, earlier := current.Add(time.Second), current.Add(-time.Second)
+
+	got := newerTimes(rec, map[string]time.Time{"a": earlier, "m": later})
+	if len(got) != 1 || !got["m"].Equal(later) {
+		t.Errorf("newerTimes of an earlier atime and later mtime = %v, want only the mtime", got)
+	}
+	if got := newerTimes(rec, map[string]time.Time{"a": current, "m": earlier}); got != nil {
+		t.Errorf("newerTimes of an equal atime and earlier mtime = %v, want nil", got)
+	}
+	if got := newerTimes(rec, map[string]time.Time{"a": later, "m": later}); len(got) != 2 {
+		t.Errorf("newerTimes of later times = %v, want both", got)
+	}
+}
+
+func TestUpdate(t *testing.T) {
+	mtime := time.Date(2021, 3, 29, 9, 8, 7, 0, time.UTC)
+	file := tempFile(t, "a.txt", "", mtime)
+
+	_, stderr, code := runGostat(t, "-update", "-verbose", "-m", mtime.Add(-time.Hour).Format(time.RFC3339), file)
+	if code != 0 {
+		t.Fatalf("-update exited %d: %s", code, stderr)
+	}
+	if !strings.Contains(stderr, "already has times at least as new") {
+		t.Errorf("-update -verbose did not report the skipped file: %q", stderr)
+	}
+	if rec, err := newFileRecord(file); err != nil || !rec.Mtime.Equal(mtime) {
+		t.Errorf("-update moved the modify time back to %s, %v", rec.Mtime, err)
+	}
+
+	// only the later of the two times is set, the access time is kept
+	later := mtime.Add(time.Hour)
+	csvFile := filepath.Join(filepath.Dir(file), "times.csv")
+	writeFile(t, csvFile, "path,mtime,atime\n"+file+","+later.Format(time.RFC3339)+","+mtime.Add(-time.Hour).Format(time.RFC3339)+"\n", mtime)
+	if _, stderr, code := runGostat(t, "-update", "-from-csv", csvFile); code != 0 {
+		t.Fatalf("-update -from-csv exited %d: %s", code, stderr)
+	}
+	if rec, err := newFileRecord(file); err != nil || !rec.Mtime.Equal(later) || !rec.Atime.Equal(mtime) {
+		t.Errorf("-update gave %s and %s, %v, want mtime %s and atime %s", rec.Mtime, rec.Atime, err, later, mtime)
+	}
+}
+
+func TestFormatRFC3339(t *testing.T) {
+	mtime := time.Date(2021, 3, 29, 9, 8, 7, 120000000, time.UTC)
+	file := tempFile(t, "a.txt", "", mtime)
+
+	out, stderr, code := runGostat(t, "-format", "rfc3339", "-tz", "UTC", file)
+	if code != 0 {
+		t.Fatalf("-format rfc3339 exited %d: %s", code, stderr)
+	}
+	if got, want := outputLine(out, "mtime :"), "mtime : "+mtime.Format(time.RFC3339Nano); got != want {
+		t.Errorf("-format rfc3339 shows %q, want %q", got, want)
+	}
+
+	resetState(t)
+	opts.format = "rfc3339"
+	local := mtime.In(time.FixedZone("", -5*3600))
+	if got, want := formatTime(local), local.Format(time.RFC3339Nano); got != want {
+		t.Errorf("formatTime = %s, want %s", got, want)
+	}
+}
+
+func TestRealPath(t *testing.T) {
+	resetState(t)
+	opts.quietErrors = true
+	dir, err := filepath.EvalSymlinks(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	target := writeFile(t, filepath.Join(dir, "target.txt"), "", time.Now())
+	link := filepath.Join(dir, "link.txt")
+	if err := os.Symlink("target.txt", link); err != nil {
+		t.Skip("symbolic links are unsupported:", err)
+	}
+
+	if got := realPath(link); got != target {
+		t.Errorf("realPath(%s) = %q, want %q", link, got, target)
+	}
+	if got := realPath(target); got != target {
+		t.Errorf("realPath(%s) = %q, want itself", target, got)
+	}
+	dangling := filepath.Join(dir, "dangling")
+	if err := os.Symlink("missing", dangling); err != nil {
+		t.Fatal(err)
+	}
+	if got := realPath(dangling); len(got) > 0 {
+		t.Errorf("realPath of a dangling link = %q, want empty", got)
+	}
+}
+
+func TestNoSize(t *testing.T) {
+	file := tempFile(t, "a.txt", "abc", time.Now())
+
+	out, stderr, code := runGostat(t, file)
+	if code != 0 || outputLine(out, "size  :") != "size  : 3" {
+		t.Fatalf("the size line is missing without -no-size: %q %s", out, stderr)
+	}
+	out, stderr, code = runGostat(t, "-no-size", file)
+	if code != 0 || len(outputLine(out, "mtime :")) == 0 {
+		t.Fatalf("-no-size exited %d with %q: %s", code, out, stderr)
+	}
+	if line := outputLine(out, "size"); len(line) > 0 {
+		t.Errorf("-no-size still shows %q", line)
+	}
+}
+
+func TestEmptyTimeStamp(t *testing.T) {
+	mtime := time.Date(2021, 3, 29, 9, 8, 7, 0, time.UTC)
+	file := tempFile(t, "a.txt", "", mtime)
+	for _, flag := range []string{"-a", "-m", "-b"} {
+		_, stderr, code := runGostat(t, flag, "", file)
+		if code == 0 {
+			t.Errorf("%s \"\" exited 0", flag)
+		}
+		if !strings.Contains(stderr, "Error: "+flag+" requires a time stamp") {
+			t.Errorf("%s \"\" gave the error %q", flag, stderr)
+		}
+	}
+	if rec, err := newFileRecord(file); err != nil || !rec.Mtime.Equal(mtime) {
+		t.Errorf("the file was changed to %s, %v", rec.Mtime, err)
+	}
+}
+
+func TestTakeSubcommand(t *testing.T) {
+	tests := []struct {
+		args     []string
+		wantSub  string
+		wantRest []string
+	}{
+		{nil, "", nil},
+		{[]string{"set", "-m", "now", "a"}, "set", []string{"-m", "now", "a"}},
+		{[]string{"show", "a"}, "show", []string{"a"}},
+		{[]string{"-m", "now", "set"}, "", []string{"-m", "now", "set"}},
+		{[]string{"settings.txt"}, "", []string{"settings.txt"}},
+	}
+	for _, tt := range tests {
+		sub, rest := takeSubcommand(tt.args)
+		if sub != tt.wantSub || !reflect.DeepEqual(rest, tt.wantRest) {
+			t.Errorf("takeSubcommand(%q) = %q, %q, want %q, %q", tt.args, sub, rest, tt.wantSub, tt.wantRest)
+		}
+	}
+}
+
+func TestSubcommands(t *testing.T) {
+	want := time.Date(2021, 3, 29, 9, 8, 7, 0, time.UTC)
+	stamp := want.Format(time.RFC3339)
+
+	// the subcommand and legacy forms set the same time
+	for _, args := range [][]string{{"set", "-m", stamp}, {"-m", stamp}} {
+		file := tempFile(t, "a.txt", "", time.Now())
+		if _, stderr, code := runGostat(t, append(args, file)...); code != 0 {
+			t.Fatalf("%q exited %d: %s", args, code, stderr)
+		}
+		if rec, err := newFileRecord(file); err != nil || !rec.Mtime.Equal(want) {
+			t.Errorf("%q set %s, %v, want %s", args, rec.Mtime, err, want)
+		}
+	}
+
+	file := tempFile(t, "a.txt", "", time.Now())
+	if out, stderr, code := runGostat(t, "show", file); code != 0 || outputLine(out, "name  :") != "name  : "+file {
+		t.Errorf("show exited %d with %q: %s", code, out, stderr)
+	}
+	if _, stderr, code := runGostat(t, "show", "-m", stamp, file); code == 0 || !strings.Contains(stderr, "show does not change file times") {
+		t.Errorf("show with -m exited %d: %s", code, stderr)
+	}
+	if _, stderr, code := runGostat(t, "set", file); code == 0 || !strings.Contains(stderr, "set requires one of") {
+		t.Errorf("set without a time exited %d: %s", code, stderr)
+	}
+	if _, stderr, _ := runGostat(t, "-verbose", "-m", stamp, file); !strings.Contains(stderr, "without the set command is deprecated") {
+		t.Errorf("the legacy form gave no deprecation note with -verbose: %q", stderr)
+	}
+}
+
+func TestJulianDay(t *testing.T) {
+	tests := []struct {
+		t    time.Time
+		want float64
+	}{
+		{time.Unix(0, 0), 2440587.5},
+		{time.Date(2000, 1, 1, 12, 0, 0, 0, time.UTC), 2451545},
+		{time.Date(2000, 1, 1, 7, 0, 0, 0, time.FixedZone("EST", -5*3600)), 2451545},
+		{time.Date(2000, 1, 2, 0, 0, 0, 0, time.UTC), 2451545.5},
+	}
+	for _, tt := range tests {
+		if got := julianDay(tt.t); got != tt.want {
+			t.Errorf("julianDay(%s) = %f, want %f", tt.t, got, tt.want)
+		}
+	}
+}
+
+func TestISOWeekDate(t *testing.T) {
+	est := time.FixedZone("EST", -5*3600)
+	tests := []struct {
+		t    time.Time
+		want string
+	}{
+		{time.Date(2025, 1, 1, 15, 4, 5, 0, est), "2025-W01-3T15:04:05-05:00"},
+		{time.Date(2024, 12, 30, 0, 0, 0, 0, time.UTC), "2025-W01-1T00:00:00Z"},
+		{time.Date(2021, 1, 3, 23, 59, 59, 500000000, time.UTC), "2020-W53-7T23:59:59.5Z"},
+	}
+	for _, tt := range tests {
+		if got := isoWeekDate(tt.t); got != tt.want {
+			t.Errorf("isoWeekDate(%s) = %s, want %s", tt.t, got, tt.want)
+		}
+	}
+}
+
+func TestAMSkew(t *testing.T) {
+	dir := t.TempDir()
+	mtime := time.Date(2021, 3, 29, 9, 8, 7, 0, time.UTC)
+	file := writeFile(t, filepath.Join(dir, "a.txt"), "", mtime)
+	if err := os.Chtimes(file, mtime.Add(90*time.Minute), mtime); err != nil {
+		t.Fatal(err)
+	}
+	behind := writeFile(t, filepath.Join(dir, "b.txt"), "", mtime)
+	if err := os.Chtimes(behind, mtime.Add(-2*time.Second), mtime); err != nil {
+		t.Fatal(err)
+	}
+
+	out, stderr, code := runGostat(t, "-am-skew", file, behind)
+	if code != 0 {
+		t.Fatalf("-am-skew exited %d: %s", code, stderr)
+	}
+	var got []string
+	for _, line := range strings.Split(out, "\n") {
+		if strings.HasPrefix(line, "skew  : ") {
+			got = append(got, line)
+		}
+	}
+	if want := []string{"skew  : 1h30m0s", "skew  : -2s"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("-am-skew shows %q, want %q", got, want)
+	}
+}
+
+func TestEnvDefaults(t *testing.T) {
+	mtime := time.Date(2021, 3, 29, 9, 8, 7, 0, time.UTC)
+	file := tempFile(t, "a.txt", "", mtime)
+
+	out, stderr, code := runGostatWith(t, []string{"GOSTAT_TZ=America/New_York", "GOSTAT_FORMAT=rfc3339"}, "", file)
+	if code != 0 {
+		t.Fatalf("GOSTAT_TZ and GOSTAT_FORMAT exited %d: %s", code, stderr)
+	}
+	if got, want := outputLine(out, "mtime :"), "mtime : 2021-03-29T05:08:07-04:00"; got != want {
+		t.Errorf("the environment gave %q, want %q", got, want)
+	}
+
+	// flags take precedence over the environment
+	out, stderr, _ = runGostatWith(t, []string{"GOSTAT_TZ=America/New_York", "GOSTAT_FORMAT=rfc3339"}, "", "-tz", "UTC", file)
+	if got, want := outputLine(out, "mtime :"), "mtime : 2021-03-29T09:08:07Z"; got != want {
+		t.Errorf("-tz UTC gave %q, want %q: %s", got, want, stderr)
+	}
+
+	if _, stderr, code := runGostatWith(t, []string{"GOSTAT_FORMAT=bogus"}, "", file); code == 0 || !strings.Contains(stderr, "GOSTAT_FORMAT must be one of") {
+		t.Errorf("an invalid GOSTAT_FORMAT exited %d: %s", code, stderr)
+	}
+}
+
+func TestQuietErrors(t *testing.T) {
+	dir := t.TempDir()
+	file := writeFile(t, filepath.Join(dir, "a.txt"), "", time.Now())
+	missing := filepath.Join(dir, "missing.txt")
+	input := missing + "\n" + file + "\n"
+
+	out, stderr, code := runGostatWith(t, nil, input, "-stdin")
+	if code != 0 || !strings.Contains(stderr, "Lstat Error: ") || !reflect.DeepEqual(shownNames(out), []string{file}) {
+		t.Errorf("a missing file exited %d with %q and errors %q", code, shownNames(out), stderr)
+	}
+	out, stderr, code = runGostatWith(t, nil, input, "-stdin", "-quiet-errors")
+	if code != 0 || len(stderr) > 0 || !reflect.DeepEqual(shownNames(out), []string{file}) {
+		t.Errorf("-quiet-errors with a missing file exited %d with %q and errors %q", code, shownNames(out), stderr)
+	}
+
+	// files refused by -protect-newer are not errors either
+	_, stderr, code = runGostat(t, "-quiet-errors", "-protect-newer", "1h", "-m", "20210329.090807", file)
+	if code != 0 || len(stderr) > 0 {
+		t.Errorf("-quiet-errors with a protected file exited %d with errors %q", code, stderr)
+	}
+}
+
+func TestHostname(t *testing.T) {
+	host, err := os.Hostname()
+	if err != nil {
+		t.Skip("no host name:", err)
+	}
+	file := tempFile(t, "a.txt", "", time.Now())
+
+	out, stderr, code := runGostat(t, "-hostname", file)
+	if code != 0 || outputLine(out, "host  :") != "host  : "+host {
+		t.Errorf("-hostname exited %d with %q: %s", code, out, stderr)
+	}
+	out, stderr, code = runGostat(t, "-hostname", "-json", file)
+	var recs []fileRecord
+	if err := json.Unmarshal([]byte(out), &recs); code != 0 || err != nil || len(recs) != 1 || recs[0].Host != host {
+		t.Errorf("-hostname -json exited %d with %q, %v: %s", code, out, err, stderr)
+	}
+
+	out, _, _ = runGostat(t, "-json", file)
+	if strings.Contains(out, `"hostname"`) || len(outputLine(out, "host")) > 0 {
+		t.Errorf("the host name was included without -hostname: %q", out)
+	}
+}
+
+func TestRoots(t *testing.T) {
+	one, two := t.TempDir(), t.TempDir()
+	old := time.Now().Add(-time.Hour)
+	a1 := writeFile(t, filepath.Join(one, "a.txt"), "", old)
+	a2 := writeFile(t, filepath.Join(two, "a.txt"), "", old)
+	b2 := writeFile(t, filepath.Join(two, "b.log"), "", old)
+
+	out, stderr, code := runGostat(t, "-root", one, "-root", two, "a.txt", "*.log")
+	if got, want := shownNames(out), []string{a1, a2, b2}; code != 0 || !reflect.DeepEqual(got, want) {
+		t.Errorf("two -root directories exited %d showing %q, want %q: %s", code, got, want, stderr)
+	}
+
+	resetState(t)
+	opts.roots = stringList{one, two}
+	if got, want := withRoots("a.txt"), []string{a1, a2}; !reflect.DeepEqual(got, want) {
+		t.Errorf("withRoots(a.txt) = %q, want %q", got, want)
+	}
+	if got := withRoots(a1); !reflect.DeepEqual(got, []string{a1}) {
+		t.Errorf("withRoots of an absolute path = %q, want it unchanged", got)
+	}
+}
+
+func TestNow(t *testing.T) {
+	now := time.Date(2025, 1, 10, 12, 0, 0, 0, time.UTC)
+	nowArg := now.Format(time.RFC3339)
+	for _, tt := range []struct{ value, want string }{
+		{"now", "2025-01-10 12:00:00 +0000 UTC\n"},
+		{"now-1d", "2025-01-09 12:00:00 +0000 UTC\n"},
+		{"@som", "2025-01-01 00:00:00 +0000 UTC\n"},
+		{"05.0830", "2025-01-05 08:30:00 +0000 UTC\n"},
+	} {
+		out, stderr, code := runGostat(t, "-tz", "UTC", "-now", nowArg, "-check-date", tt.value)
+		if code != 0 || out != tt.want {
+			t.Errorf("-now %s -check-date %s = %q, exit code %d: %s, want %q", nowArg, tt.value, out, code, stderr, tt.want)
+		}
+	}
+
+	// ages are measured from -now, so runs at different moments give the same histogram
+	dir := t.TempDir()
+	a := writeFile(t, filepath.Join(dir, "a.txt"), "", now.Add(-30*time.Minute))
+	b := writeFile(t, filepath.Join(dir, "b.txt"), "", now.Add(-3*24*time.Hour))
+	var first string
+	for i := 0; i < 2; i++ {
+		out, stderr, code := runGostat(t, "-now", nowArg, "-histogram", a, b)
+		if code != 0 {
+			t.Fatalf("-histogram exit code %d: %s", code, stderr)
+		}
+		if i == 0 {
+			first = out
+			continue
+		}
+		if out != first {
+			t.Errorf("-now -histogram gave %q, then %q", first, out)
+		}
+	}
+	for _, row := range []string{"<1h", "<1w"} {
+		if fields := strings.Fields(outputLine(first, row)); len(fields) < 2 || fields[1] != "1" {
+			t.Errorf("-now -histogram gave %q, want one file in %s", first, row)
+		}
+	}
+
+	if _, stderr, code := runGostat(t, "-now", "bogus", "-check-date", "now"); code == 0 || !strings.Contains(stderr, "-now: invalid time stamp: bogus") {
+		t.Errorf("an invalid -now exited %d: %s", code, stderr)
+	}
+}
+
+func TestFixFuture(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Date(2025, 1, 10, 0, 0, 0, 0, time.UTC)
+	past := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	future := writeFile(t, filepath.Join(dir, "future.txt"), "", now.Add(24*time.Hour))
+	normal := writeFile(t, filepath.Join(dir, "normal.txt"), "", past)
+	// only the modify time of this one is in the future
+	mixed := writeFile(t, filepath.Join(dir, "mixed.txt"), "", past)
+	if err := os.Chtimes(mixed, past, now.Add(time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, stderr, code := runGostat(t, "-now", now.Format(time.RFC3339), "-fix-future", future, normal, mixed); code != 0 {
+		t.Fatalf("-fix-future exited %d: %s", code, stderr)
+	}
+	tests := []struct {
+		file         string
+		atime, mtime time.Time
+	}{
+		{future, now, now},
+		{normal, past, past},
+		{mixed, past, now},
+	}
+	for _, tt := range tests {
+		rec, err := newFileRecord(tt.file)
+		if err != nil || !rec.Atime.Equal(tt.atime) || !rec.Mtime.Equal(tt.mtime) {
+			t.Errorf("%s has atime %s and mtime %s, %v, want %s and %s", tt.file, rec.Atime, rec.Mtime, err, tt.atime, tt.mtime)
+		}
+	}
+}
+
+func TestCanonicalTimes(t *testing.T) {
+	mtime := time.Date(2021, 3, 29, 9, 8, 7, 987654321, time.UTC)
+	file := tempFile(t, "a.txt", "", mtime)
+
+	out, stderr, code := runGostat(t, "-canonical-times", "-tz", "UTC", "-format", "rfc3339", file)
+	if code != 0 {
+		t.Fatalf("-canonical-times exited %d: %s", code, stderr)
+	}
+	if got, want := outputLine(out, "mtime :"), "mtime : 2021-03-29T09:08:07Z"; got != want {
+		t.Errorf("-canonical-times shows %q, want %q", got, want)
+	}
+	for _, line := range strings.Split(out, "\n") {
+		if strings.Contains(line, ".") && !strings.HasPrefix(line, "name") {
+			t.Errorf("-canonical-times shows a fractional second: %q", line)
+		}
+	}
+	// the file itself keeps its fractional second
+	if rec, err := newFileRecord(file); err != nil || !rec.Mtime.Equal(mtime) {
+		t.Errorf("the file was changed to %s, %v", rec.Mtime, err)
+	}
+
+	out, _, _ = runGostat(t, "-canonical-times", "-json", file)
+	var recs []fileRecord
+	if err := json.Unmarshal([]byte(out), &recs); err != nil || len(recs) != 1 || recs[0].Mtime.Nanosecond() != 0 || recs[0].Atime.Nanosecond() != 0 {
+		t.Errorf("-canonical-times -json = %q, %v", out, err)
+	}
+}
+
+func TestSortKey(t *testing.T) {
+	buf := resetState(t)
+	opts.sortKey = "m"
+	est := time.FixedZone("EST", -5*3600)
+	times := []time.Time{
+		time.Date(2021, 3, 29, 9, 8, 7, 0, time.UTC),
+		time.Date(2021, 3, 29, 6, 0, 0, 0, est),
+		time.Date(1999, 12, 31, 23, 59, 59, 0, time.UTC),
+		time.Date(2021, 3, 29, 9, 8, 8, 0, time.UTC),
+		time.Date(2021, 10, 1, 0, 0, 0, 0, time.UTC),
+	}
+	for i, mtime := range times {
+		printRecord(fileRecord{Name: fmt.Sprintf("f%d", i), Mtime: mtime}, nil)
+	}
+
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	if lines[1] != "20210329110000 f1" {
+		t.Errorf("the sort key is not in UTC: %q", lines[1])
+	}
+	sort.Strings(lines)
+	byTime := make([]int, len(times))
+	for i := range byTime {
+		byTime[i] = i
+	}
+	sort.Slice(byTime, func(i, j int) bool { return times[byTime[i]].Before(times[byTime[j]]) })
+	for i, line := range lines {
+		if want := fmt.Sprintf("f%d", byTime[i]); !strings.HasSuffix(line, " "+want) {
+			t.Errorf("line %d in text order is %q, want %s in time order", i, line, want)
+		}
+	}
+}
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"fmt"
+	"runtime"
+	"strings"
+	"time"
+)
+
+// touchLayout - the touch -d format understood by both GNU and BSD touch
+// unlike touch -t, it keeps fractional seconds, so times are copied to the nanosecond
+const touchLayout string = "2006-01-02T15:04:05.999999999Z"
+
+// shellQuote - quote s for a POSIX shell
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// powershellQuote - quote s for PowerShell
+func powershellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
+
+// touchCommands - return the commands which would give file the times in newTimes
+// touch is used on Unix with times in UTC, and PowerShell on Windows with times in local time
+func touchCommands(file string, newTimes map[string]time.Time) []string {
+	atime, hasAccess := newTimes["a"]
+	mtime, hasModify := newTimes["m"]
+
+	if runtime.GOOS == "windows" {
+		var cmds []string
+		if hasAccess {
+			cmds = append(cmds, fmt.Sprintf("(Get-Item -LiteralPath %s).LastAccessTime = %s", powershellQuote(file), powershellQuote(atime.Local().Format("2006-01-02 15:04:05.0000000"))))
+		}
+		if hasModify {
+			cmds = append(cmds, fmt.Sprintf("(Get-Item -LiteralPath %s).LastWriteTime = %s", powershellQuote(file), powershellQuote(mtime.Local().Format("2006-01-02 15:04:05.0000000"))))
+		}
+		return cmds
+	}
+
+	if hasAccess && hasModify && atime.Equal(mtime) {
+		return []string{fmt.Sprintf("touch -c -d %s %s", atime.UTC().Format(touchLayout), shellQuote(file))}
+	}
+	var cmds []string
+	if hasAccess {
+		cmds = append(cmds, fmt.Sprintf("touch -c -a -d %s %s", atime.UTC().Format(touchLayout), shellQuote(file)))
+	}
+	if hasModify {
+		cmds = append(cmds, fmt.Sprintf("touch -c -m -d %s %s", mtime.UTC().Format(touchLayout), shellQuote(file)))
+	}
+	return cmds
+}
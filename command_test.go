@@ -0,0 +1,81 @@
+package main
+
+import (
+	"os/exec"
+	"reflect"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTouchCommands(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("PowerShell commands are generated on Windows")
+	}
+	atime := time.Date(2021, 3, 29, 9, 8, 7, 500, time.FixedZone("EST", -5*3600))
+	mtime := time.Date(2022, 1, 2, 3, 4, 5, 0, time.UTC)
+	file := "it's here.txt"
+
+	tests := []struct {
+		name     string
+		newTimes map[string]time.Time
+		want     []string
+	}{
+		{"both equal", map[string]time.Time{"a": mtime, "m": mtime}, []string{
+			`touch -c -d 2022-01-02T03:04:05Z 'it'\''s here.txt'`,
+		}},
+		{"both different", map[string]time.Time{"a": atime, "m": mtime}, []string{
+			`touch -c -a -d 2021-03-29T14:08:07.0000005Z 'it'\''s here.txt'`,
+			`touch -c -m -d 2022-01-02T03:04:05Z 'it'\''s here.txt'`,
+		}},
+		{"modify only", map[string]time.Time{"m": mtime}, []string{
+			`touch -c -m -d 2022-01-02T03:04:05Z 'it'\''s here.txt'`,
+		}},
+		{"none", map[string]time.Time{}, nil},
+	}
+	for _, tt := range tests {
+		if got := touchCommands(file, tt.newTimes); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: touchCommands = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestPowershellQuote(t *testing.T) {
+	if got, want := powershellQuote("it's"), "'it''s'"; got != want {
+		t.Errorf("powershellQuote = %s, want %s", got, want)
+	}
+}
+
+func TestShowCommandNanoseconds(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("PowerShell commands are generated on Windows")
+	}
+	want := time.Date(2021, 3, 29, 9, 8, 7, 123456789, time.UTC)
+	file := tempFile(t, "a.txt", "", time.Now())
+
+	stdout, stderr, code := runGostat(t, "-show-command", "-m", want.Format(time.RFC3339Nano), file)
+	if code != 0 {
+		t.Fatalf("-show-command exited %d: %s", code, stderr)
+	}
+	cmd := "touch -c -m -d 2021-03-29T09:08:07.123456789Z " + shellQuote(file)
+	if !strings.HasPrefix(stdout, cmd+"\n") {
+		t.Errorf("-show-command output %q, want it to start with %q", stdout, cmd)
+	}
+	rec, err := newFileRecord(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !rec.Mtime.Equal(want) {
+		t.Skipf("this file system stores %s for %s", rec.Mtime, want)
+	}
+
+	// the shown command gives another file the same time, to the nanosecond
+	other := tempFile(t, "b.txt", "", time.Now())
+	if err := exec.Command("sh", "-c", strings.Replace(cmd, shellQuote(file), shellQuote(other), 1)).Run(); err != nil {
+		t.Skipf("touch -d is unavailable: %s", err)
+	}
+	if rec, err = newFileRecord(other); err != nil || !rec.Mtime.Equal(want) {
+		t.Errorf("the shown command set %s, %v, want %s", rec.Mtime, err, want)
+	}
+}
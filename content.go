@@ -0,0 +1,66 @@
+package main
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// contentTimes - return a timeResolver which sets each file's modify time to the date on its first line
+// layout is a Go time layout such as 2006-01-02 15:04:05; files whose first line does not match are skipped
+func contentTimes(layout string) timeResolver {
+	return func(rec fileRecord) (map[string]time.Time, error) {
+		line, err := firstLine(rec.Name)
+		if err != nil {
+			return nil, err
+		}
+		t, err := time.ParseInLocation(layout, line, time.Local)
+		if err != nil {
+			logError("Content Warning: %s: first line is not a time stamp, skipping: %s\n", rec.Name, err)
+			return nil, nil
+		}
+		return map[string]time.Time{"m": t}, nil
+	}
+}
+
+// firstLine - return the first line of a file without surrounding white space
+func firstLine(file string) (string, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	if !scanner.Scan() {
+		return "", scanner.Err()
+	}
+	return strings.TrimSpace(scanner.Text()), nil
+}
+
+// nameTimes - return a timeResolver which sets each file's modify time to a date in its name
+// the first capture group of re, or all of the match without one, is read with layout;
+// files whose base name does not match are skipped
+func nameTimes(re *regexp.Regexp, layout string) timeResolver {
+	return func(rec fileRecord) (map[string]time.Time, error) {
+		base := filepath.Base(rec.Name)
+		m := re.FindStringSubmatch(base)
+		if m == nil {
+			logError("Name Warning: %s: no date in name, skipping\n", rec.Name)
+			return nil, nil
+		}
+		value := m[0]
+		if len(m) > 1 {
+			value = m[1]
+		}
+		t, err := time.ParseInLocation(layout, value, time.Local)
+		if err != nil {
+			logError("Name Warning: %s: %s does not match -name-layout, skipping\n", rec.Name, value)
+			return nil, nil
+		}
+		return map[string]time.Time{"m": t}, nil
+	}
+}
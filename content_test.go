@@ -0,0 +1,69 @@
+package main
+
+import (
+	"path/filepath"
+	"regexp"
+	"testing"
+	"time"
+)
+
+func TestContentTimes(t *testing.T) {
+	resetState(t)
+	opts.quietErrors = true
+	dir := t.TempDir()
+	resolve := contentTimes("2006-01-02 15:04:05")
+	want := time.Date(2021, 3, 29, 9, 8, 7, 0, time.Local)
+
+	dated := writeFile(t, filepath.Join(dir, "dated.txt"), "  2021-03-29 09:08:07 \nbody\n", time.Now())
+	got, err := resolve(fileRecord{Name: dated})
+	if err != nil || len(got) != 1 || !got["m"].Equal(want) {
+		t.Errorf("contentTimes of a dated file = %v, %v, want mtime %s", got, err, want)
+	}
+
+	for _, contents := range []string{"no date here\n2021-03-29 09:08:07\n", ""} {
+		file := writeFile(t, filepath.Join(dir, "undated.txt"), contents, time.Now())
+		if got, err := resolve(fileRecord{Name: file}); got != nil || err != nil {
+			t.Errorf("contentTimes of %q = %v, %v, want it skipped", contents, got, err)
+		}
+	}
+
+	if _, err := resolve(fileRecord{Name: filepath.Join(dir, "missing")}); err == nil {
+		t.Errorf("contentTimes of a missing file did not fail")
+	}
+}
+
+func TestNameTimes(t *testing.T) {
+	resetState(t)
+	opts.quietErrors = true
+	want := time.Date(2021, 3, 29, 0, 0, 0, 0, time.Local)
+
+	grouped := nameTimes(regexp.MustCompile(`IMG_(\d{8})_`), "20060102")
+	whole := nameTimes(regexp.MustCompile(`\d{4}-\d{2}-\d{2}`), "2006-01-02")
+	tests := []struct {
+		resolve timeResolver
+		name    string
+		found   bool
+	}{
+		{grouped, filepath.Join("2019", "IMG_20210329_120000.jpg"), true},
+		{whole, "report 2021-03-29.pdf", true},
+		{grouped, "IMG_0001.jpg", false},
+		{grouped, "IMG_20211399_120000.jpg", false},
+		{whole, filepath.Join("2021-03-29", "notes.txt"), false},
+	}
+	for _, tt := range tests {
+		got, err := tt.resolve(fileRecord{Name: tt.name})
+		if err != nil {
+			t.Errorf("nameTimes(%s): %s", tt.name, err)
+			continue
+		}
+		if !tt.found {
+			if got != nil {
+				t.Errorf("nameTimes(%s) = %v, want it skipped", tt.name, got)
+			}
+			continue
+		}
+		if len(got) != 1 || !got["m"].Equal(want) {
+			t.Errorf("nameTimes(%s) = %v, want mtime %s", tt.name, got, want)
+		}
+	}
+}
@@ -0,0 +1,118 @@
+package main
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"time"
+)
+
+// csvColumns - the columns written by -csv and required by -from-csv, other columns are ignored when read
+var csvColumns = []string{"path", "mtime", "atime"}
+
+// writeCSV - output the name, modify time, and access time of each record, in a form -from-csv can read back
+func writeCSV() {
+	w := csv.NewWriter(output)
+	w.Write(csvColumns)
+	for _, rec := range records {
+		// an empty atime, as for zip entries, leaves the access time as is when read back by -from-csv
+		atime := ""
+		if !rec.Atime.IsZero() {
+			atime = rec.Atime.Format(time.RFC3339Nano)
+		}
+		w.Write([]string{rec.Name, rec.Mtime.Format(time.RFC3339Nano), atime})
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		log.Fatalf("CSV Error: %s\n", err)
+	}
+}
+
+// readCSVTimes - return the file names in a CSV file, in order, along with the times to set for each
+// an empty mtime or atime leaves that time as is; rows which can not be parsed are reported and skipped
+func readCSVTimes(path string) ([]string, map[string]map[string]time.Time, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer f.Close()
+
+	r := csv.NewReader(f)
+	r.FieldsPerRecord = -1
+	header, err := r.Read()
+	if err != nil {
+		return nil, nil, fmt.Errorf("%s: can not read header: %w", path, err)
+	}
+	index := make(map[string]int)
+	for i, name := range header {
+		index[name] = i
+	}
+	for _, name := range csvColumns {
+		if _, found := index[name]; !found {
+			return nil, nil, fmt.Errorf("%s: header is missing the %s column", path, name)
+		}
+	}
+
+	var files []string
+	csvTimes := make(map[string]map[string]time.Time)
+	for {
+		row, err := r.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			logError("CSV Error: %s: %s\n", path, err)
+			continue
+		}
+		file, newTimes, err := csvRow(row, index)
+		if err != nil {
+			line, _ := r.FieldPos(0)
+			logError("CSV Error: %s line %d: %s\n", path, line, err)
+			continue
+		}
+		if _, found := csvTimes[file]; !found {
+			files = append(files, file)
+		}
+		csvTimes[file] = newTimes
+	}
+	return files, csvTimes, nil
+}
+
+// csvRow - return the file name and times given in a single CSV row
+func csvRow(row []string, index map[string]int) (string, map[string]time.Time, error) {
+	cell := func(name string) string {
+		if i := index[name]; i < len(row) {
+			return row[i]
+		}
+		return ""
+	}
+	file := cell("path")
+	if len(file) == 0 {
+		return "", nil, fmt.Errorf("path is empty")
+	}
+	newTimes := make(map[string]time.Time)
+	for field, name := range map[string]string{"m": "mtime", "a": "atime"} {
+		value := cell(name)
+		if len(value) == 0 {
+			continue
+		}
+		t, err := createDate(value)
+		if err != nil {
+			return "", nil, fmt.Errorf("%s: %w", name, err)
+		}
+		newTimes[field] = t
+	}
+	if len(newTimes) == 0 {
+		return "", nil, fmt.Errorf("%s: neither mtime nor atime is given", file)
+	}
+	return file, newTimes, nil
+}
+
+// csvTimes - return a timeResolver which gives each file the times read by readCSVTimes
+func csvTimes(byFile map[string]map[string]time.Time) timeResolver {
+	return func(rec fileRecord) (map[string]time.Time, error) {
+		return byFile[rec.Name], nil
+	}
+}
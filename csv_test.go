@@ -0,0 +1,53 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestCSVRoundTrip(t *testing.T) {
+	buf := resetState(t)
+	opts.quietErrors = true
+	mtime := time.Date(2021, 3, 29, 9, 8, 7, 123456789, time.UTC)
+	atime := mtime.Add(time.Hour)
+	records = []fileRecord{
+		{Name: "a.txt", Mtime: mtime, Atime: atime},
+		{Name: `b, "c".txt`, Mtime: atime, Atime: mtime},
+	}
+
+	writeCSV()
+	path := filepath.Join(t.TempDir(), "times.csv")
+	// a row with only a modify time and one that can not be parsed
+	extra := "d.txt,2020-01-02T03:04:05Z,\ne.txt,yesterday,\n"
+	if err := os.WriteFile(path, append(buf.Bytes(), extra...), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	files, byFile, err := readCSVTimes(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"a.txt", `b, "c".txt`, "d.txt"}; !reflect.DeepEqual(files, want) {
+		t.Errorf("readCSVTimes files = %q, want %q", files, want)
+	}
+	for _, rec := range records {
+		got := byFile[rec.Name]
+		if !got["m"].Equal(rec.Mtime) || !got["a"].Equal(rec.Atime) {
+			t.Errorf("%s read back as %v, want mtime %s and atime %s", rec.Name, got, rec.Mtime, rec.Atime)
+		}
+	}
+	if got := byFile["d.txt"]; len(got) != 1 || !got["m"].Equal(time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)) {
+		t.Errorf("d.txt read back as %v, want only its mtime", got)
+	}
+
+	noPath := filepath.Join(t.TempDir(), "bad.csv")
+	if err := os.WriteFile(noPath, []byte("name,mtime,atime\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := readCSVTimes(noPath); err == nil {
+		t.Errorf("a header without a path column was accepted")
+	}
+}
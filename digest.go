@@ -0,0 +1,101 @@
+package main
+
+import (
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"io"
+	"os"
+	"sort"
+	"strings"
+)
+
+// digestAlgorithms - the hashes accepted by -dupes-by-content
+var digestAlgorithms = map[string]func() hash.Hash{
+	"md5":    md5.New,
+	"sha1":   sha1.New,
+	"sha256": sha256.New,
+	"sha512": sha512.New,
+}
+
+// digestNames - return the names of digestAlgorithms, sorted
+func digestNames() []string {
+	names := make([]string, 0, len(digestAlgorithms))
+	for name := range digestAlgorithms {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// fileDigest - return the hex encoded hash of a file's contents, read in a stream
+func fileDigest(file string, newHash func() hash.Hash) (string, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	h := newHash()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// writeContentDupes - output groups of files with identical contents, along with each file's modify time
+// only regular files sharing a size are hashed, and files which can not be read are reported and left out;
+// reading a fifo or device could block or never end, so those are skipped along with directories
+func writeContentDupes() {
+	bySize := make(map[int64][]fileRecord)
+	for _, rec := range records {
+		if !rec.mode.IsRegular() {
+			continue
+		}
+		bySize[rec.Size] = append(bySize[rec.Size], rec)
+	}
+
+	groups := make(map[string][]fileRecord)
+	var digests []string
+	for _, same := range bySize {
+		if len(same) < 2 {
+			continue
+		}
+		for _, rec := range same {
+			digest, err := fileDigest(rec.Name, digestAlgorithms[opts.dupesDigest])
+			if err != nil {
+				logError("Digest Error: %s\n", err)
+				continue
+			}
+			if _, found := groups[digest]; !found {
+				digests = append(digests, digest)
+			}
+			groups[digest] = append(groups[digest], rec)
+		}
+	}
+	sort.Strings(digests)
+
+	for _, digest := range digests {
+		group := groups[digest]
+		if len(group) < 2 {
+			continue
+		}
+		fmt.Fprintf(output, "%s:%s, %s bytes (%d files)\n", opts.dupesDigest, digest, Format(group[0].Size), len(group))
+		sort.Slice(group, func(i, j int) bool { return group[i].Mtime.Before(group[j].Mtime) })
+		for _, rec := range group {
+			fmt.Fprintf(output, "  %s  %s\n", formatTime(rec.Mtime), rec.Name)
+		}
+	}
+}
+
+// validDigest - return an error naming the accepted algorithms when name is not one of them
+func validDigest(name string) error {
+	if _, found := digestAlgorithms[name]; !found {
+		return fmt.Errorf("unknown algorithm %s, use one of: %s", name, strings.Join(digestNames(), ", "))
+	}
+	return nil
+}
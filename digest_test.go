@@ -0,0 +1,81 @@
+package main
+
+import (
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWriteContentDupes(t *testing.T) {
+	buf := resetState(t)
+	opts.format = "rfc3339"
+	opts.dupesDigest = "sha256"
+	dir := t.TempDir()
+	t1 := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	t2 := time.Date(2021, 3, 29, 9, 8, 7, 0, time.UTC)
+	files := []struct {
+		name, contents string
+		mtime          time.Time
+	}{
+		{"a.txt", "hello", t2},
+		{"b.txt", "hello", t1},
+		{"c.txt", "world", t1},
+		{"d.txt", "hello!", t1},
+	}
+	for _, f := range files {
+		file := writeFile(t, filepath.Join(dir, f.name), f.contents, f.mtime)
+		rec, err := newFileRecord(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		rec.Mtime = rec.Mtime.UTC()
+		records = append(records, rec)
+	}
+
+	writeContentDupes()
+	want := "sha256:2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824, 5 bytes (2 files)\n" +
+		"  2020-01-02T03:04:05Z  " + records[1].Name + "\n" +
+		"  2021-03-29T09:08:07Z  " + records[0].Name + "\n"
+	if got := buf.String(); got != want {
+		t.Errorf("writeContentDupes = %q, want %q", got, want)
+	}
+
+	if err := validDigest("crc32"); err == nil {
+		t.Errorf("an unknown digest was accepted")
+	}
+}
+
+func TestContentDupesSkipsFifo(t *testing.T) {
+	mkfifo, err := exec.LookPath("mkfifo")
+	if err != nil {
+		t.Skip("mkfifo is unavailable:", err)
+	}
+	dir := t.TempDir()
+	mtime := time.Date(2021, 3, 29, 9, 8, 7, 0, time.UTC)
+	a := writeFile(t, filepath.Join(dir, "a.txt"), "", mtime)
+	b := writeFile(t, filepath.Join(dir, "b.txt"), "", mtime)
+	// an empty file and a fifo have the same size, and reading the fifo would block forever
+	if err := exec.Command(mkfifo, filepath.Join(dir, "fifo")).Run(); err != nil {
+		t.Fatal(err)
+	}
+
+	type result struct {
+		out, stderr string
+		code        int
+	}
+	done := make(chan result, 1)
+	go func() {
+		out, stderr, code := runGostat(t, "-R", "-dupes-by-content", "sha256", dir)
+		done <- result{out, stderr, code}
+	}()
+	select {
+	case r := <-done:
+		if r.code != 0 || !strings.Contains(r.out, "(2 files)") || !strings.Contains(r.out, a) || !strings.Contains(r.out, b) || strings.Contains(r.out, "fifo") {
+			t.Errorf("-dupes-by-content exited %d with %q, want only the two empty files: %s", r.code, r.out, r.stderr)
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatal("-dupes-by-content blocked reading a fifo")
+	}
+}
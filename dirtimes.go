@@ -0,0 +1,48 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// dirMtimes - for -touch-containing-dir, the newest modify time set on a file within each directory
+var dirMtimes = make(map[string]time.Time)
+
+// noteDirMtime - remember mtime for the directory containing file, keeping the newest one
+func noteDirMtime(file string, mtime time.Time) {
+	dir := filepath.Dir(file)
+	if t, found := dirMtimes[dir]; !found || mtime.After(t) {
+		dirMtimes[dir] = mtime
+	}
+}
+
+// touchContainingDirs - set the modify time of each directory noted by noteDirMtime
+// this is done once, after all files have been changed, since changing a file can itself update its directory
+func touchContainingDirs() {
+	dirs := make([]string, 0, len(dirMtimes))
+	for dir := range dirMtimes {
+		dirs = append(dirs, dir)
+	}
+	sort.Strings(dirs)
+	for _, dir := range dirs {
+		info, err := os.Stat(dir)
+		if err != nil {
+			logError("Stat Error: %s\n", err)
+			continue
+		}
+		atime := getFileTimes(dir)["a"]
+		if atime.IsZero() {
+			atime = info.ModTime()
+		}
+		if err = chtimes(dir, atime, dirMtimes[dir]); err != nil {
+			logError("os.Chtimes Error: %s\n", err)
+			continue
+		}
+		if opts.verbose {
+			fmt.Fprintf(os.Stderr, "set mtime of directory %s to %s\n", dir, formatTime(dirMtimes[dir]))
+		}
+	}
+}
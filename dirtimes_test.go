@@ -0,0 +1,49 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestTouchContainingDir(t *testing.T) {
+	dir := t.TempDir()
+	older := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	newer := time.Date(2021, 3, 29, 9, 8, 7, 0, time.UTC)
+	a := writeFile(t, filepath.Join(dir, "a.txt"), "", time.Now())
+	b := writeFile(t, filepath.Join(dir, "b.txt"), "", time.Now())
+
+	if _, stderr, code := runGostat(t, "-touch-containing-dir", "-m", older.Format(time.RFC3339), a); code != 0 {
+		t.Fatalf("-touch-containing-dir exited %d: %s", code, stderr)
+	}
+	if info, err := os.Stat(dir); err != nil || !info.ModTime().Equal(older) {
+		t.Fatalf("the directory has mtime %v, %v, want %s", info.ModTime(), err, older)
+	}
+
+	// a second run moves the directory's time to the one given to b
+	if _, stderr, code := runGostat(t, "-touch-containing-dir", "-m", newer.Format(time.RFC3339), b); code != 0 {
+		t.Fatalf("-touch-containing-dir exited %d: %s", code, stderr)
+	}
+	if info, err := os.Stat(dir); err != nil || !info.ModTime().Equal(newer) {
+		t.Errorf("the directory has mtime %v, %v, want %s", info.ModTime(), err, newer)
+	}
+}
+
+func TestNoteDirMtime(t *testing.T) {
+	saved := dirMtimes
+	t.Cleanup(func() { dirMtimes = saved })
+	dirMtimes = make(map[string]time.Time)
+	t1 := time.Date(2021, 3, 29, 9, 8, 7, 0, time.UTC)
+	t2 := t1.Add(time.Hour)
+
+	noteDirMtime(filepath.Join("d", "a"), t2)
+	noteDirMtime(filepath.Join("d", "b"), t1)
+	noteDirMtime(filepath.Join("e", "c"), t1)
+	if got := dirMtimes["d"]; !got.Equal(t2) {
+		t.Errorf("directory d noted %s, want the newest %s", got, t2)
+	}
+	if got := dirMtimes["e"]; !got.Equal(t1) {
+		t.Errorf("directory e noted %s, want %s", got, t1)
+	}
+}
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// epochUnits - the units accepted by -epoch-unit, besides auto
+var epochUnits = map[string]time.Duration{
+	"s":  time.Second,
+	"ms": time.Millisecond,
+	"us": time.Microsecond,
+	"ns": time.Nanosecond,
+}
+
+// guessEpochUnit - choose a unit from the number of digits in an epoch time stamp
+// 13 digits is milliseconds and 16 is microseconds for any date between 2001 and 2286
+func guessEpochUnit(digits string) time.Duration {
+	switch n := len(strings.TrimPrefix(digits, "-")); {
+	case n <= 12:
+		return time.Second
+	case n <= 15:
+		return time.Millisecond
+	case n <= 18:
+		return time.Microsecond
+	}
+	return time.Nanosecond
+}
+
+// parseEpoch - return the time for a count of units since the Unix epoch
+// unit is one of epochUnits or auto, which guesses the unit from the number of digits
+func parseEpoch(digits, unit string) (time.Time, error) {
+	n, err := strconv.ParseInt(digits, 10, 64)
+	if err != nil {
+		return time.Time{}, err
+	}
+	size, found := epochUnits[unit]
+	if !found {
+		if unit != "auto" {
+			return time.Time{}, fmt.Errorf("unknown epoch unit: %s", unit)
+		}
+		size = guessEpochUnit(digits)
+	}
+	perSecond := int64(time.Second / size)
+	return time.Unix(n/perSecond, (n%perSecond)*int64(size)), nil
+}
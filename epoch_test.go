@@ -0,0 +1,55 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestGuessEpochUnit(t *testing.T) {
+	tests := []struct {
+		digits string
+		want   time.Duration
+	}{
+		{"0", time.Second},
+		{"1616998087", time.Second},
+		{"-1616998087", time.Second},
+		{"1616998087123", time.Millisecond},
+		{"1616998087123456", time.Microsecond},
+		{"1616998087123456789", time.Nanosecond},
+	}
+	for _, tt := range tests {
+		if got := guessEpochUnit(tt.digits); got != tt.want {
+			t.Errorf("guessEpochUnit(%s) = %s, want %s", tt.digits, got, tt.want)
+		}
+	}
+}
+
+func TestParseEpoch(t *testing.T) {
+	want := time.Date(2021, 3, 29, 6, 8, 7, 123456000, time.UTC)
+	tests := []struct {
+		digits, unit string
+		want         time.Time
+	}{
+		{"1616998087", "s", want.Truncate(time.Second)},
+		{"1616998087123", "ms", want.Truncate(time.Millisecond)},
+		{"1616998087123456", "us", want},
+		{"1616998087123456000", "ns", want},
+		{"1616998087", "auto", want.Truncate(time.Second)},
+		{"1616998087123", "auto", want.Truncate(time.Millisecond)},
+		{"1616998087123456", "auto", want},
+		{"-1000", "ms", time.Date(1969, 12, 31, 23, 59, 59, 0, time.UTC)},
+	}
+	for _, tt := range tests {
+		got, err := parseEpoch(tt.digits, tt.unit)
+		if err != nil || !got.Equal(tt.want) {
+			t.Errorf("parseEpoch(%s, %s) = %s, %v, want %s", tt.digits, tt.unit, got, err, tt.want)
+		}
+	}
+
+	if _, err := parseEpoch("1616998087", "days"); err == nil {
+		t.Errorf("an unknown unit was accepted")
+	}
+	if _, err := parseEpoch("16169x", "s"); err == nil {
+		t.Errorf("a non-numeric epoch was accepted")
+	}
+}
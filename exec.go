@@ -0,0 +1,98 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// execFailures - number of -exec commands which did not exit successfully
+var execFailures int
+
+// splitCommand - split a command line into arguments, honoring single quotes,
+// double quotes and backslash escapes; no other shell processing is performed
+func splitCommand(cmdLine string) ([]string, error) {
+	var args []string
+	var current strings.Builder
+	inArg := false
+	var quote rune
+	escaped := false
+
+	for _, r := range cmdLine {
+		switch {
+		case escaped:
+			current.WriteRune(r)
+			escaped = false
+		case r == '\\' && quote != '\'':
+			escaped = true
+			inArg = true
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			} else {
+				current.WriteRune(r)
+			}
+		case r == '\'' || r == '"':
+			quote = r
+			inArg = true
+		case r == ' ' || r == '\t' || r == '\n':
+			if inArg {
+				args = append(args, current.String())
+				current.Reset()
+				inArg = false
+			}
+		default:
+			current.WriteRune(r)
+			inArg = true
+		}
+	}
+	if quote != 0 {
+		return nil, fmt.Errorf("unterminated %c quote in command: %s", quote, cmdLine)
+	}
+	if escaped {
+		return nil, fmt.Errorf("trailing backslash in command: %s", cmdLine)
+	}
+	if inArg {
+		args = append(args, current.String())
+	}
+	if len(args) == 0 {
+		return nil, fmt.Errorf("empty command")
+	}
+	return args, nil
+}
+
+// runExec - run the -exec command for a single file, replacing each {} with the file name
+// the command is run directly instead of through a shell so file names are never reinterpreted
+func runExec(cmdArgs []string, file string) bool {
+	expanded := make([]string, len(cmdArgs))
+	for i, arg := range cmdArgs {
+		expanded[i] = strings.ReplaceAll(arg, "{}", file)
+	}
+	cmd := exec.Command(expanded[0], expanded[1:]...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		logError("exec Error: %s: %s\n", strings.Join(expanded, " "), err)
+		execFailures += 1
+		return false
+	}
+	return true
+}
+
+// timeFromCommand - run a command and parse its output with createDate
+func timeFromCommand(cmdLine string) (time.Time, error) {
+	cmdArgs, err := splitCommand(cmdLine)
+	if err != nil {
+		return time.Time{}, err
+	}
+	cmd := exec.Command(cmdArgs[0], cmdArgs[1:]...)
+	cmd.Stderr = os.Stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return time.Time{}, fmt.Errorf("command failed: %s: %w", cmdLine, err)
+	}
+	return createDate(strings.TrimSpace(string(out)))
+}
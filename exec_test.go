@@ -0,0 +1,82 @@
+package main
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSplitCommand(t *testing.T) {
+	tests := []struct {
+		cmdLine string
+		want    []string
+	}{
+		{"echo {}", []string{"echo", "{}"}},
+		{"  ls   -l  ", []string{"ls", "-l"}},
+		{`sh -c 'echo "a b"'`, []string{"sh", "-c", `echo "a b"`}},
+		{`echo "it's"`, []string{"echo", "it's"}},
+		{`echo a\ b`, []string{"echo", "a b"}},
+		{`echo ''`, []string{"echo", ""}},
+	}
+	for _, tt := range tests {
+		got, err := splitCommand(tt.cmdLine)
+		if err != nil {
+			t.Errorf("splitCommand(%q): %s", tt.cmdLine, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("splitCommand(%q) = %q, want %q", tt.cmdLine, got, tt.want)
+		}
+	}
+
+	for _, bad := range []string{"", "   ", "echo 'open", `echo "open`, `echo \`} {
+		if _, err := splitCommand(bad); err == nil {
+			t.Errorf("splitCommand(%q) did not fail", bad)
+		}
+	}
+}
+
+func TestExecRunsOncePerFile(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh is not available")
+	}
+	files := tempFiles(t, 3)
+	log := filepath.Join(t.TempDir(), "runs.log")
+	args := append([]string{"-exec", "sh -c 'echo {} >> " + log + "'"}, files...)
+	if _, stderr, code := runGostat(t, args...); code != 0 {
+		t.Fatalf("exit code %d: %s", code, stderr)
+	}
+	data, err := os.ReadFile(log)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	if !reflect.DeepEqual(lines, files) {
+		t.Errorf("-exec ran for %q, want %q", lines, files)
+	}
+}
+
+func TestTimeFromCommand(t *testing.T) {
+	if _, err := exec.LookPath("echo"); err != nil {
+		t.Skip("echo is not available")
+	}
+	resetState(t)
+	got, err := timeFromCommand("echo '  20210329.090807  '")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := time.Date(2021, 3, 29, 9, 8, 7, 0, time.Local); !got.Equal(want) {
+		t.Errorf("timeFromCommand = %s, want %s", got, want)
+	}
+
+	if _, err := timeFromCommand("echo not a date"); err == nil {
+		t.Errorf("output which is not a time stamp did not fail")
+	}
+	if _, err := timeFromCommand("false"); err == nil {
+		t.Errorf("a failing command did not fail")
+	}
+}
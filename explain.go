@@ -0,0 +1,43 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// writeExplanation - output, in plain sentences, what would be done for -explain
+// patterns are the command line arguments, allFiles what they matched, and plan describes the
+// change to be made or is empty when times are only displayed
+func writeExplanation(patterns, allFiles []string, plan string) {
+	if len(patterns) > 0 {
+		fmt.Fprintf(output, "Patterns: %s\n", strings.Join(patterns, ", "))
+	}
+	if opts.recursive {
+		walk := "Recursive: yes"
+		if opts.maxDepth >= 0 {
+			walk += fmt.Sprintf(", at most %d level(s) deep", opts.maxDepth)
+		}
+		fmt.Fprintln(output, walk)
+	}
+	fmt.Fprintf(output, "Matched: %d file(s)\n", len(allFiles))
+
+	if len(plan) == 0 {
+		fmt.Fprintln(output, "Operation: display the times of each file")
+		fmt.Fprintln(output, "Destructive: no, files are only read")
+		return
+	}
+	fmt.Fprintf(output, "Operation: %s\n", plan)
+	if opts.dryRun {
+		fmt.Fprintln(output, "Destructive: no, -dry-run only shows what would be set")
+	} else {
+		fmt.Fprintln(output, "Destructive: yes, the current times of matched files will be overwritten")
+	}
+	if opts.create && !opts.dryRun {
+		fmt.Fprintln(output, "Create: files which do not exist will be created")
+	}
+	if !opts.force && !opts.dryRun {
+		if file, dir, count := refuseProtected(allFiles); count > 0 {
+			fmt.Fprintf(output, "Refused: %d file(s) in protected system directories, such as %s in %s, unless -force is given\n", count, file, dir)
+		}
+	}
+}
@@ -0,0 +1,41 @@
+package main
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestExplain(t *testing.T) {
+	dir := t.TempDir()
+	mtime := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	a := writeFile(t, filepath.Join(dir, "a.txt"), "", mtime)
+	b := writeFile(t, filepath.Join(dir, "b.txt"), "", mtime)
+
+	out, stderr, code := runGostat(t, "-explain", "-tz", "UTC", "-format", "rfc3339", "-b", "20210329.090807", a, b)
+	if code != 0 {
+		t.Fatalf("-explain exited %d: %s", code, stderr)
+	}
+	for _, want := range []string{
+		"Matched: 2 file(s)",
+		"Operation: set atime to 2021-03-29T09:08:07Z, mtime to 2021-03-29T09:08:07Z",
+		"Destructive: yes, the current times of matched files will be overwritten",
+	} {
+		prefix, _, _ := strings.Cut(want, ":")
+		if got := outputLine(out, prefix+":"); got != want {
+			t.Errorf("-explain shows %q, want %q", got, want)
+		}
+	}
+	// nothing is changed
+	for _, file := range []string{a, b} {
+		if rec, err := newFileRecord(file); err != nil || !rec.Mtime.Equal(mtime) {
+			t.Errorf("-explain changed %s to %s, %v", file, rec.Mtime, err)
+		}
+	}
+
+	out, _, _ = runGostat(t, "-explain", a)
+	if !strings.Contains(out, "Operation: display the times of each file\n") || !strings.Contains(out, "Destructive: no") {
+		t.Errorf("-explain without a change:\n%s", out)
+	}
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// sizeUnits - multipliers for the suffixes accepted by parseSize
+var sizeUnits = map[string]float64{
+	"":  1,
+	"K": 1 << 10,
+	"M": 1 << 20,
+	"G": 1 << 30,
+	"T": 1 << 40,
+}
+
+// validSize - a number with an optional K, M, G, or T suffix, optionally followed by B or iB
+var validSize = regexp.MustCompile(`(?i)^(\d+(?:\.\d+)?)\s*([KMGT]?)(?:i?B)?$`)
+
+// parseSize - convert a size such as 500, 10K, 1.5MB, or 2GiB into bytes
+// all units are powers of 1024
+func parseSize(s string) (int64, error) {
+	m := validSize.FindStringSubmatch(strings.TrimSpace(s))
+	if m == nil {
+		return 0, fmt.Errorf("invalid size: %s", s)
+	}
+	n, err := strconv.ParseFloat(m[1], 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid size: %s", s)
+	}
+	bytes := n * sizeUnits[strings.ToUpper(m[2])]
+	// math.MaxInt64 rounds up to 1<<63 as a float64, which no longer fits in an int64
+	if bytes >= math.MaxInt64 {
+		return 0, fmt.Errorf("size too large: %s", s)
+	}
+	return int64(bytes), nil
+}
+
+// fileAge - return how long before startTime, which is changed by -now, t was
+func fileAge(t time.Time) time.Duration {
+	return startTime.Sub(t)
+}
+
+// afterBound - return true when t is after bound, or with -inclusive equal to it
+func afterBound(t, bound time.Time) bool {
+	return t.After(bound) || (opts.inclusive && t.Equal(bound))
+}
+
+// beforeBound - return true when t is before bound, or with -inclusive equal to it
+func beforeBound(t, bound time.Time) bool {
+	return t.Before(bound) || (opts.inclusive && t.Equal(bound))
+}
+
+// fileTypeLetters - the kinds of files accepted by -type, in the style of find -type
+const fileTypeLetters string = "fdlpscb"
+
+// matchesType - return true when mode is one of the kinds of files in letters
+func matchesType(mode os.FileMode, letters string) bool {
+	letter, _ := fileKind(mode)
+	return letter != 0 && strings.ContainsRune(letters, letter)
+}
+
+// selected - return true when a file passes all of the filters given on the command line
+func selected(rec fileRecord) bool {
+	if opts.minSize >= 0 && rec.Size < opts.minSize {
+		return false
+	}
+	if opts.maxSize >= 0 && rec.Size > opts.maxSize {
+		return false
+	}
+	if len(opts.types) > 0 && !matchesType(rec.mode, opts.types) {
+		return false
+	}
+	if len(opts.ignore) > 0 && ignored(rec.Name, rec.mode.IsDir()) {
+		return false
+	}
+	if opts.uid >= 0 && rec.uid != opts.uid {
+		return false
+	}
+	if opts.gid >= 0 && rec.gid != opts.gid {
+		return false
+	}
+	if !opts.createdAfter.IsZero() || !opts.createdBefore.IsZero() {
+		if rec.Btime == nil {
+			return opts.createdMissing
+		}
+		if !opts.createdAfter.IsZero() && !afterBound(*rec.Btime, opts.createdAfter) {
+			return false
+		}
+		if !opts.createdBefore.IsZero() && !beforeBound(*rec.Btime, opts.createdBefore) {
+			return false
+		}
+	}
+	return true
+}
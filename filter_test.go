@@ -0,0 +1,197 @@
+package main
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseSize(t *testing.T) {
+	tests := []struct {
+		s    string
+		want int64
+	}{
+		{"0", 0},
+		{"500", 500},
+		{"10K", 10 << 10},
+		{"1.5M", 3 << 19},
+		{"1.5MB", 3 << 19},
+		{"2GiB", 2 << 30},
+		{"2 g", 2 << 30},
+		{"1T", 1 << 40},
+		{"8388607T", 8388607 << 40},
+	}
+	for _, tt := range tests {
+		got, err := parseSize(tt.s)
+		if err != nil {
+			t.Errorf("parseSize(%q): %s", tt.s, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("parseSize(%q) = %d, want %d", tt.s, got, tt.want)
+		}
+	}
+
+	for _, bad := range []string{"", "-1", "10X", "1.5.5K", "8388608T", "9999999T"} {
+		if got, err := parseSize(bad); err == nil {
+			t.Errorf("parseSize(%q) = %d, want an error", bad, got)
+		}
+	}
+}
+
+func TestSizeFilters(t *testing.T) {
+	dir := t.TempDir()
+	old := time.Now().Add(-time.Hour)
+	small := writeFile(t, filepath.Join(dir, "small"), strings.Repeat("x", 100), old)
+	medium := writeFile(t, filepath.Join(dir, "medium"), strings.Repeat("x", 2048), old)
+	large := writeFile(t, filepath.Join(dir, "large"), strings.Repeat("x", 10000), old)
+	empty := writeFile(t, filepath.Join(dir, "empty"), "", old)
+	all := []string{small, medium, large, empty}
+
+	tests := []struct {
+		args []string
+		want []string
+	}{
+		{[]string{"-min-size", "1K"}, []string{medium, large}},
+		{[]string{"-max-size", "2K"}, []string{small, medium, empty}},
+		{[]string{"-min-size", "1K", "-max-size", "2K"}, []string{medium}},
+		{[]string{"-empty"}, []string{empty}},
+	}
+	for _, tt := range tests {
+		out, stderr, _ := runGostat(t, append(tt.args, all...)...)
+		got := shownNames(out)
+		if strings.Join(got, " ") != strings.Join(tt.want, " ") {
+			t.Errorf("%v selected %v, want %v %s", tt.args, got, tt.want, stderr)
+		}
+	}
+
+	// one byte too large for an int64 must be refused rather than wrapping around and matching everything
+	if _, _, code := runGostat(t, append([]string{"-min-size", "8388608T"}, all...)...); code == 0 {
+		t.Errorf("-min-size 8388608T was accepted")
+	}
+}
+
+func TestTypeFilter(t *testing.T) {
+	root := t.TempDir()
+	old := time.Now().Add(-time.Hour)
+	sub := filepath.Join(root, "sub")
+	if err := os.Mkdir(sub, 0755); err != nil {
+		t.Fatal(err)
+	}
+	file := writeFile(t, filepath.Join(sub, "a.txt"), "", old)
+	link := filepath.Join(root, "link")
+	if err := os.Symlink(file, link); err != nil {
+		t.Skip("symbolic links are unsupported:", err)
+	}
+	var fifo string
+	if mkfifo, err := exec.LookPath("mkfifo"); err == nil {
+		fifo = filepath.Join(root, "fifo")
+		if err := exec.Command(mkfifo, fifo).Run(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		types string
+		want  []string
+	}{
+		{"d", []string{root, sub}},
+		{"f", []string{file}},
+		{"l", []string{link}},
+		{"fl", []string{link, file}},
+	}
+	if len(fifo) > 0 {
+		tests = append(tests, struct {
+			types string
+			want  []string
+		}{"p", []string{fifo}})
+	}
+	for _, tt := range tests {
+		out, stderr, code := runGostat(t, "-R", "-type", tt.types, root)
+		got := shownNames(out)
+		if code != 0 || strings.Join(got, " ") != strings.Join(tt.want, " ") {
+			t.Errorf("-type %s selected %v, want %v: %s", tt.types, got, tt.want, stderr)
+		}
+	}
+
+	for letter, mode := range map[rune]os.FileMode{'f': 0, 'd': os.ModeDir, 's': os.ModeSocket, 'c': os.ModeDevice | os.ModeCharDevice, 'b': os.ModeDevice} {
+		if !matchesType(mode, string(letter)) || matchesType(mode, strings.ReplaceAll(fileTypeLetters, string(letter), "")) {
+			t.Errorf("matchesType(%s) does not select only -type %c", mode, letter)
+		}
+	}
+	if matchesType(os.ModeIrregular, fileTypeLetters) {
+		t.Errorf("an irregular file matched -type %s", fileTypeLetters)
+	}
+}
+
+func TestSelectedCreated(t *testing.T) {
+	resetState(t)
+	bound := time.Date(2021, 3, 29, 0, 0, 0, 0, time.UTC)
+	before, after := bound.Add(-time.Hour), bound.Add(time.Hour)
+	withBtime := func(b time.Time) fileRecord { return fileRecord{Name: "f", Btime: &b} }
+
+	opts.createdAfter = bound
+	if !selected(withBtime(after)) || selected(withBtime(before)) || selected(withBtime(bound)) {
+		t.Errorf("-created-after did not select only later birth times")
+	}
+	if selected(fileRecord{Name: "f"}) {
+		t.Errorf("a file without a birth time was selected")
+	}
+	opts.createdMissing = true
+	if !selected(fileRecord{Name: "f"}) {
+		t.Errorf("a file without a birth time was not selected with -created-missing")
+	}
+
+	opts.createdAfter, opts.createdBefore, opts.createdMissing = time.Time{}, bound, false
+	if !selected(withBtime(before)) || selected(withBtime(after)) || selected(fileRecord{Name: "f"}) {
+		t.Errorf("-created-before did not select only earlier birth times")
+	}
+
+	// without a created bound the birth time is not needed
+	opts.createdBefore = time.Time{}
+	if !selected(fileRecord{Name: "f"}) || !selected(withBtime(after)) {
+		t.Errorf("files were filtered by birth time with no bound given")
+	}
+}
+
+func TestInclusiveBounds(t *testing.T) {
+	resetState(t)
+	bound := time.Date(2021, 3, 29, 9, 8, 7, 0, time.UTC)
+	tests := []struct {
+		t                     time.Time
+		inclusive             bool
+		wantAfter, wantBefore bool
+	}{
+		{bound, false, false, false},
+		{bound, true, true, true},
+		{bound.Add(time.Nanosecond), false, true, false},
+		{bound.Add(-time.Nanosecond), true, false, true},
+		{bound.In(time.FixedZone("EST", -5*3600)), true, true, true},
+	}
+	for _, tt := range tests {
+		opts.inclusive = tt.inclusive
+		if got := afterBound(tt.t, bound); got != tt.wantAfter {
+			t.Errorf("afterBound(%s) with inclusive %v = %v, want %v", tt.t, tt.inclusive, got, tt.wantAfter)
+		}
+		if got := beforeBound(tt.t, bound); got != tt.wantBefore {
+			t.Errorf("beforeBound(%s) with inclusive %v = %v, want %v", tt.t, tt.inclusive, got, tt.wantBefore)
+		}
+	}
+}
+
+func TestInclusiveStale(t *testing.T) {
+	now := time.Date(2025, 1, 10, 0, 0, 0, 0, time.UTC)
+	file := tempFile(t, "edge.txt", "", now.Add(-24*time.Hour))
+
+	out, _, code := runGostat(t, "-now", now.Format(time.RFC3339), "-stale", "24h", file)
+	if code != 0 || len(out) > 0 {
+		t.Errorf("a file exactly at the -stale boundary exited %d with %q, want it fresh", code, out)
+	}
+	out, _, code = runGostat(t, "-now", now.Format(time.RFC3339), "-stale", "24h", "-inclusive", file)
+	if code != 1 || !strings.HasPrefix(out, file+": modified 24h0m0s ago") {
+		t.Errorf("-inclusive at the -stale boundary exited %d with %q, want it stale", code, out)
+	}
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// gitLog - return what git outputs for the committer date of the latest commit in dir, which tests replace with a fake git
+var gitLog = func(dir string) ([]byte, error) {
+	return exec.Command("git", "-C", dir, "log", "-1", "--format=%cI").Output()
+}
+
+// gitCommitDate - return the committer date of the latest commit in the repository holding dir
+func gitCommitDate(dir string) (time.Time, error) {
+	out, err := gitLog(dir)
+	if errors.Is(err, exec.ErrNotFound) {
+		return time.Time{}, fmt.Errorf("git not found in PATH")
+	}
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%s is not in a git repository with commits: %w", dir, err)
+	}
+	date := strings.TrimSpace(string(out))
+	if len(date) == 0 {
+		return time.Time{}, fmt.Errorf("%s is in a git repository without commits", dir)
+	}
+	return createDate(date)
+}
+
+// gitTimes - return a timeResolver which sets each file's modify time to the date of the latest git commit
+// git is run in repo when it is given, otherwise in each file's directory, once per directory;
+// files outside of a repository are skipped with a warning
+func gitTimes(repo string) timeResolver {
+	dates := make(map[string]time.Time)
+	failed := make(map[string]bool)
+	return func(rec fileRecord) (map[string]time.Time, error) {
+		dir := repo
+		if len(dir) == 0 {
+			dir = filepath.Dir(rec.Name)
+			if info, err := os.Stat(rec.Name); err == nil && info.IsDir() {
+				dir = rec.Name
+			}
+		}
+		if failed[dir] {
+			return nil, nil
+		}
+		t, found := dates[dir]
+		if !found {
+			var err error
+			if t, err = gitCommitDate(dir); err != nil {
+				failed[dir] = true
+				logError("Git Error: %s, skipping its files\n", err)
+				return nil, nil
+			}
+			dates[dir] = t
+		}
+		return map[string]time.Time{"m": t}, nil
+	}
+}
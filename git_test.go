@@ -0,0 +1,89 @@
+package main
+
+import (
+	"errors"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// errNotRepository - what the fake git of fakeGit fails with outside of a repository
+var errNotRepository = errors.New("exit status 128")
+
+// fakeGit - replace gitLog with one answering from dates, keyed by directory, and return the directories it was run in
+// a directory missing from dates is answered as git does outside of a repository
+func fakeGit(t *testing.T, dates map[string]string) *[]string {
+	t.Helper()
+	saved := gitLog
+	t.Cleanup(func() { gitLog = saved })
+	var calls []string
+	gitLog = func(dir string) ([]byte, error) {
+		calls = append(calls, dir)
+		date, found := dates[dir]
+		if !found {
+			return nil, errNotRepository
+		}
+		return []byte(date), nil
+	}
+	return &calls
+}
+
+func TestGitCommitDate(t *testing.T) {
+	resetState(t)
+	fakeGit(t, map[string]string{"repo": "2021-03-29T09:08:07+02:00\n", "empty": ""})
+
+	want := time.Date(2021, 3, 29, 7, 8, 7, 0, time.UTC)
+	if got, err := gitCommitDate("repo"); err != nil || !got.Equal(want) {
+		t.Errorf("gitCommitDate(repo) = %s, %v, want %s", got, err, want)
+	}
+	if _, err := gitCommitDate("empty"); err == nil || !strings.Contains(err.Error(), "without commits") {
+		t.Errorf("gitCommitDate of a repository without commits gave %v", err)
+	}
+	if _, err := gitCommitDate("elsewhere"); !errors.Is(err, errNotRepository) || !strings.Contains(err.Error(), "not in a git repository") {
+		t.Errorf("gitCommitDate outside of a repository gave %v", err)
+	}
+
+	// git is not installed
+	gitLog = func(dir string) ([]byte, error) {
+		return nil, &exec.Error{Name: "git", Err: exec.ErrNotFound}
+	}
+	if _, err := gitCommitDate("repo"); err == nil || err.Error() != "git not found in PATH" {
+		t.Errorf("gitCommitDate without git gave %v", err)
+	}
+}
+
+func TestGitTimes(t *testing.T) {
+	resetState(t)
+	opts.quietErrors = true
+	repo, other := t.TempDir(), t.TempDir()
+	calls := fakeGit(t, map[string]string{repo: "2021-03-29T09:08:07Z"})
+	want := time.Date(2021, 3, 29, 9, 8, 7, 0, time.UTC)
+
+	// git is run once for each directory, including one which is not in a repository
+	resolve := gitTimes("")
+	for _, file := range []string{filepath.Join(repo, "a.txt"), filepath.Join(repo, "b.txt"), repo} {
+		if got, err := resolve(fileRecord{Name: file}); err != nil || len(got) != 1 || !got["m"].Equal(want) {
+			t.Errorf("the times for %s = %v, %v, want mtime %s", file, got, err, want)
+		}
+	}
+	for _, file := range []string{filepath.Join(other, "a.txt"), filepath.Join(other, "b.txt")} {
+		if got, err := resolve(fileRecord{Name: file}); err != nil || got != nil {
+			t.Errorf("the times for %s outside of a repository = %v, %v, want it skipped", file, got, err)
+		}
+	}
+	if len(*calls) != 2 || (*calls)[0] != repo || (*calls)[1] != other {
+		t.Errorf("git was run in %q, want once in %s and once in %s", *calls, repo, other)
+	}
+
+	// with -git-repo, every file is given the date of that repository
+	*calls = nil
+	resolve = gitTimes(repo)
+	if got, err := resolve(fileRecord{Name: filepath.Join(other, "a.txt")}); err != nil || !got["m"].Equal(want) {
+		t.Errorf("-git-repo gave %v, %v, want mtime %s", got, err, want)
+	}
+	if len(*calls) != 1 || (*calls)[0] != repo {
+		t.Errorf("-git-repo ran git in %q, want %s", *calls, repo)
+	}
+}
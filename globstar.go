@@ -0,0 +1,90 @@
+package main
+
+import (
+	"io/fs"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// expandDoubleStar - return the files matching a pattern in which a ** path element matches
+// any number of directories, such as src/**/*.go; the walk beneath the directory before the
+// first ** goes no deeper than -max-depth
+func expandDoubleStar(pattern string) ([]string, error) {
+	parts := strings.Split(filepath.ToSlash(pattern), "/")
+	i := 0
+	for i < len(parts) && parts[i] != "**" {
+		i += 1
+	}
+	base := strings.Join(parts[:i], "/")
+	if len(base) == 0 {
+		base = "."
+		if i > 0 {
+			base = "/"
+		}
+	}
+	bases, err := filepath.Glob(filepath.FromSlash(base))
+	if err != nil {
+		return nil, err
+	}
+
+	var matches []string
+	for _, dir := range bases {
+		err := filepath.WalkDir(dir, func(file string, d fs.DirEntry, err error) error {
+			if err != nil {
+				logError("Walk Error: %s\n", err)
+				return nil
+			}
+			rel, err := filepath.Rel(dir, file)
+			if err != nil {
+				return nil
+			}
+			var relParts []string
+			if rel != "." {
+				relParts = strings.Split(filepath.ToSlash(rel), "/")
+			}
+			if matchParts(parts[i:], relParts) {
+				matches = append(matches, file)
+			}
+			if d.IsDir() && opts.maxDepth >= 0 && pathDepth(dir, file) >= opts.maxDepth {
+				return filepath.SkipDir
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return matches, nil
+}
+
+// pathDepth - return how many directories below root a path is, 0 for root itself
+func pathDepth(root, file string) int {
+	rel, err := filepath.Rel(root, file)
+	if err != nil || rel == "." {
+		return 0
+	}
+	return strings.Count(filepath.ToSlash(rel), "/") + 1
+}
+
+// matchParts - return true when the path elements in name match those of a pattern, where ** matches zero or more elements
+func matchParts(pattern, name []string) bool {
+	if len(pattern) == 0 {
+		return len(name) == 0
+	}
+	if pattern[0] == "**" {
+		for skip := 0; skip <= len(name); skip++ {
+			if matchParts(pattern[1:], name[skip:]) {
+				return true
+			}
+		}
+		return false
+	}
+	if len(name) == 0 {
+		return false
+	}
+	if ok, err := path.Match(pattern[0], name[0]); err != nil || !ok {
+		return false
+	}
+	return matchParts(pattern[1:], name[1:])
+}
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMatchParts(t *testing.T) {
+	tests := []struct {
+		pattern, name string
+		want          bool
+	}{
+		{"**/*.go", "main.go", true},
+		{"**/*.go", "a/b/c/main.go", true},
+		{"**/*.go", "a/b/main.c", false},
+		{"a/**/c", "a/c", true},
+		{"a/**/c", "a/x/y/c", true},
+		{"a/**/c", "b/x/c", false},
+		{"**", "", true},
+		{"**", "a/b", true},
+		{"*/**/*.txt", "x.txt", false},
+		{"**/x/**/*.txt", "a/x/b/c.txt", true},
+	}
+	split := func(s string) []string {
+		if len(s) == 0 {
+			return nil
+		}
+		return strings.Split(s, "/")
+	}
+	for _, tt := range tests {
+		if got := matchParts(split(tt.pattern), split(tt.name)); got != tt.want {
+			t.Errorf("matchParts(%s, %s) = %v, want %v", tt.pattern, tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestExpandDoubleStar(t *testing.T) {
+	resetState(t)
+	root := t.TempDir()
+	old := time.Now().Add(-time.Hour)
+	for _, dir := range []string{"src/a/b", "docs"} {
+		if err := os.MkdirAll(filepath.Join(root, filepath.FromSlash(dir)), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	var want []string
+	for _, name := range []string{"src/main.go", "src/a/util.go", "src/a/b/deep.go", "src/a/readme.md", "docs/doc.go"} {
+		file := writeFile(t, filepath.Join(root, filepath.FromSlash(name)), "", old)
+		if strings.HasPrefix(name, "src/") && strings.HasSuffix(name, ".go") {
+			want = append(want, file)
+		}
+	}
+	pattern := filepath.Join(root, "src", "**", "*.go")
+
+	got, err := expandDoubleStar(pattern)
+	// WalkDir visits files in lexical order: a/b/deep.go, a/util.go, main.go
+	if wantOrder := []string{want[2], want[1], want[0]}; err != nil || !reflect.DeepEqual(got, wantOrder) {
+		t.Errorf("expandDoubleStar(%s) = %q, %v, want %q", pattern, got, err, wantOrder)
+	}
+
+	depths := []struct {
+		maxDepth int
+		want     []string
+	}{
+		{0, nil},
+		{1, []string{want[0]}},
+		{2, []string{want[1], want[0]}},
+	}
+	for _, d := range depths {
+		opts.maxDepth = d.maxDepth
+		got, err := expandDoubleStar(pattern)
+		if err != nil || !reflect.DeepEqual(got, d.want) {
+			t.Errorf("-max-depth %d: expandDoubleStar = %q, %v, want %q", d.maxDepth, got, err, d.want)
+		}
+	}
+}
+
+func TestPathDepth(t *testing.T) {
+	root := filepath.FromSlash("/x/y")
+	for file, want := range map[string]int{"/x/y": 0, "/x/y/a": 1, "/x/y/a/b/c": 3} {
+		if got := pathDepth(root, filepath.FromSlash(file)); got != want {
+			t.Errorf("pathDepth(%s, %s) = %d, want %d", root, file, got, want)
+		}
+	}
+}
@@ -4,4 +4,4 @@ go 1.21.5
 
 require github.com/djherbis/times v1.6.0
 
-require golang.org/x/sys v0.0.0-20220615213510-4f61da869c0c // indirect
+require golang.org/x/sys v0.0.0-20220615213510-4f61da869c0c
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ignorePattern - a single line from an -ignore-file
+type ignorePattern struct {
+	glob    string
+	negate  bool
+	dirOnly bool
+}
+
+// readIgnoreFile - return the patterns in a .gitignore style file
+// blank lines and lines starting with # are skipped; a leading ! re-includes matching files
+// and a trailing / only matches directories
+func readIgnoreFile(path string) ([]ignorePattern, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var patterns []ignorePattern
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if len(line) == 0 || strings.HasPrefix(line, "#") {
+			continue
+		}
+		var p ignorePattern
+		if strings.HasPrefix(line, "!") {
+			p.negate = true
+			line = line[1:]
+		}
+		if strings.HasSuffix(line, "/") {
+			p.dirOnly = true
+			line = strings.TrimRight(line, "/")
+		}
+		p.glob = strings.TrimPrefix(line, "/")
+		if len(p.glob) == 0 {
+			continue
+		}
+		if _, err := filepath.Match(p.glob, ""); err != nil {
+			return nil, err
+		}
+		patterns = append(patterns, p)
+	}
+	return patterns, scanner.Err()
+}
+
+// matches - return true when the pattern matches path or one of the directories containing it
+// patterns without a / are compared to each path element, others to the trailing elements of path
+func (p ignorePattern) matches(path string, isDir bool) bool {
+	elems := strings.Split(filepath.ToSlash(filepath.Clean(path)), "/")
+	width := strings.Count(p.glob, "/") + 1
+	for end := len(elems); end >= width; end-- {
+		// only the last element can be something other than a directory
+		if p.dirOnly && end == len(elems) && !isDir {
+			continue
+		}
+		if ok, _ := filepath.Match(p.glob, strings.Join(elems[end-width:end], "/")); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// ignored - return true when path is excluded by opts.ignore, the last matching pattern wins
+func ignored(path string, isDir bool) bool {
+	result := false
+	for _, p := range opts.ignore {
+		if p.matches(path, isDir) {
+			result = !p.negate
+		}
+	}
+	return result
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestReadIgnoreFile(t *testing.T) {
+	resetState(t)
+	path := writeFile(t, filepath.Join(t.TempDir(), ".gostatignore"), "# build output\n\n*.log\n!keep.log\n/build/\ndocs/*.tmp\n", time.Now())
+	patterns, err := readIgnoreFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []ignorePattern{
+		{glob: "*.log"},
+		{glob: "keep.log", negate: true},
+		{glob: "build", dirOnly: true},
+		{glob: "docs/*.tmp"},
+	}
+	if !reflect.DeepEqual(patterns, want) {
+		t.Fatalf("readIgnoreFile = %+v, want %+v", patterns, want)
+	}
+
+	opts.ignore = patterns
+	tests := []struct {
+		path  string
+		isDir bool
+		want  bool
+	}{
+		{"a.log", false, true},
+		{"sub/a.log", false, true},
+		{"keep.log", false, false},
+		{"build", true, true},
+		{"build", false, false},
+		{"build/out.o", false, true},
+		{"src/main.go", false, false},
+		{"docs/a.tmp", false, true},
+		{"a.tmp", false, false},
+	}
+	for _, tt := range tests {
+		if got := ignored(filepath.FromSlash(tt.path), tt.isDir); got != tt.want {
+			t.Errorf("ignored(%s, %v) = %v, want %v", tt.path, tt.isDir, got, tt.want)
+		}
+	}
+
+	bad := writeFile(t, filepath.Join(t.TempDir(), "bad"), "[\n", time.Now())
+	if _, err := readIgnoreFile(bad); err == nil {
+		t.Errorf("a malformed pattern was accepted")
+	}
+}
+
+func TestIgnoreFileWalk(t *testing.T) {
+	root := t.TempDir()
+	old := time.Now().Add(-time.Hour)
+	build := filepath.Join(root, "build")
+	if err := os.Mkdir(build, 0755); err != nil {
+		t.Fatal(err)
+	}
+	writeFile(t, filepath.Join(build, "out.o"), "", old)
+	writeFile(t, filepath.Join(root, "a.log"), "", old)
+	kept := writeFile(t, filepath.Join(root, "main.go"), "", old)
+	ignoreFile := writeFile(t, filepath.Join(t.TempDir(), "ignore"), "build/\n*.log\n", old)
+
+	out, stderr, code := runGostat(t, "-R", "-files-only", "-ignore-file", ignoreFile, root)
+	if got := shownNames(out); code != 0 || strings.Join(got, " ") != kept {
+		t.Errorf("-ignore-file selected %v, want only %s: %s", got, kept, stderr)
+	}
+}
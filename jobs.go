@@ -0,0 +1,32 @@
+package main
+
+import "sync"
+
+// statChunkSize - files given to statAll at once for each job, which bounds both memory
+// and the work thrown away when -limit or an interrupt stops processing early
+const statChunkSize = 64
+
+// statResult - the outcome of statFile for a single file
+type statResult struct {
+	rec fileRecord
+	err error
+}
+
+// statAll - return the result of statFile for each file, in the same order as files
+// no more than opts.jobs files are stat'ed at once so that huge trees do not run out of file descriptors
+func statAll(files []string) []statResult {
+	results := make([]statResult, len(files))
+	sem := make(chan struct{}, opts.jobs)
+	var wg sync.WaitGroup
+	for i, file := range files {
+		sem <- struct{}{}
+		wg.Add(1)
+		go func(i int, file string) {
+			defer wg.Done()
+			results[i].rec, results[i].err = statFile(file)
+			<-sem
+		}(i, file)
+	}
+	wg.Wait()
+	return results
+}
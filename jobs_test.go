@@ -0,0 +1,56 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestStatAll(t *testing.T) {
+	resetState(t)
+	saved := readRecord
+	t.Cleanup(func() { readRecord = saved })
+	var mu sync.Mutex
+	running, most := 0, 0
+	errMissing := errors.New("missing")
+	readRecord = func(file string) (fileRecord, error) {
+		mu.Lock()
+		running += 1
+		if running > most {
+			most = running
+		}
+		mu.Unlock()
+		time.Sleep(time.Millisecond)
+		mu.Lock()
+		running -= 1
+		mu.Unlock()
+		if file == "f13" {
+			return fileRecord{}, errMissing
+		}
+		return fileRecord{Name: file}, nil
+	}
+
+	opts.jobs = 3
+	files := make([]string, 200)
+	for i := range files {
+		files[i] = fmt.Sprintf("f%d", i)
+	}
+	results := statAll(files)
+	if len(results) != len(files) {
+		t.Fatalf("statAll returned %d results for %d files", len(results), len(files))
+	}
+	for i, result := range results {
+		if files[i] == "f13" {
+			if !errors.Is(result.err, errMissing) {
+				t.Errorf("result %d has error %v, want %v", i, result.err, errMissing)
+			}
+		} else if result.err != nil || result.rec.Name != files[i] {
+			t.Errorf("result %d = %+v, want %s", i, result, files[i])
+		}
+	}
+	if most > opts.jobs {
+		t.Errorf("%d files were stat'ed at once with -j %d", most, opts.jobs)
+	}
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"encoding/json"
+	"io"
+	"log"
+)
+
+// flusher - a writer holding output in memory until Flush is called, such as the -batch-size buffer
+type flusher interface {
+	Flush() error
+}
+
+// flushingWriter - pushes out everything written to it right away, so that each -jsonl record
+// reaches a slow reader as soon as its file has been read instead of when a buffer fills
+type flushingWriter struct {
+	w io.Writer
+}
+
+// Write - write p, then flush the underlying writer when it buffers
+func (fw flushingWriter) Write(p []byte) (int, error) {
+	n, err := fw.w.Write(p)
+	if err != nil {
+		return n, err
+	}
+	if f, ok := fw.w.(flusher); ok {
+		err = f.Flush()
+	}
+	return n, err
+}
+
+// writeJSONLine - output a single record as one line of compact JSON for -jsonl
+// json.Encoder writes each record with one call, so a line is never split between flushes
+func writeJSONLine(rec fileRecord) {
+	enc := json.NewEncoder(flushingWriter{w: output})
+	if err := enc.Encode(rec); err != nil {
+		log.Fatalf("JSON Error: %s\n", err)
+	}
+}
+
+// truncated - true once -max-results has cut the output short, for the truncated field of -json
+var truncated bool
+
+// reportTruncated - say that -max-results cut the output short, with -jsonl as a last line of {"truncated":true}
+// -json output is flagged when it is written, by writeJSON
+func reportTruncated() {
+	truncated = true
+	if opts.json {
+		return
+	}
+	if opts.jsonl {
+		enc := json.NewEncoder(flushingWriter{w: output})
+		if err := enc.Encode(map[string]bool{"truncated": true}); err != nil {
+			log.Fatalf("JSON Error: %s\n", err)
+		}
+		return
+	}
+	log.Printf("Note: stopped after %d results, more files matched\n", opts.maxResults)
+}
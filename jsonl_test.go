@@ -0,0 +1,44 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"testing"
+	"time"
+)
+
+func TestJSONLIncremental(t *testing.T) {
+	resetState(t)
+	opts.jsonl = true
+	pr, pw := io.Pipe()
+	t.Cleanup(func() { pw.Close() })
+	// buffered as it is with -batch-size, which must not hold back -jsonl records
+	output = bufio.NewWriter(pw)
+	lines := make(chan string)
+	go func() {
+		r := bufio.NewReader(pr)
+		for {
+			line, err := r.ReadString('\n')
+			if err != nil {
+				close(lines)
+				return
+			}
+			lines <- line
+		}
+	}()
+
+	mtime := time.Date(2021, 3, 29, 9, 8, 7, 0, time.UTC)
+	for _, name := range []string{"a.txt", "b.txt"} {
+		printRecord(fileRecord{Name: name, Mtime: mtime, Atime: mtime}, nil)
+		select {
+		case line := <-lines:
+			var rec fileRecord
+			if err := json.Unmarshal([]byte(line), &rec); err != nil || rec.Name != name || !rec.Mtime.Equal(mtime) {
+				t.Errorf("the reader received %q, %v, want the record for %s", line, err, name)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("the record for %s did not reach the reader", name)
+		}
+	}
+}
@@ -0,0 +1,10 @@
+//go:build !windows
+
+package main
+
+// display labels for the time stamps returned by getFileTimes
+// ctime is the inode change time and btime is the birth (creation) time
+const (
+	birthLabel  string = "btime"
+	changeLabel string = "ctime"
+)
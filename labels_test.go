@@ -0,0 +1,19 @@
+package main
+
+import (
+	"runtime"
+	"testing"
+)
+
+func TestLabels(t *testing.T) {
+	wantBirth, wantChange := "btime", "ctime"
+	if runtime.GOOS == "windows" {
+		wantBirth, wantChange = "create", "change"
+	}
+	if birthLabel != wantBirth || changeLabel != wantChange {
+		t.Errorf("labels on %s are %q and %q, want %q and %q", runtime.GOOS, birthLabel, changeLabel, wantBirth, wantChange)
+	}
+	if fieldNames["b"] != birthLabel || fieldNames["c"] != changeLabel {
+		t.Errorf("fieldNames do not use the platform labels: %v", fieldNames)
+	}
+}
@@ -0,0 +1,9 @@
+package main
+
+// display labels for the time stamps returned by getFileTimes
+// NTFS records a creation time and a separate metadata change time, which
+// Unix users know as btime and ctime; use the Windows terminology instead
+const (
+	birthLabel  string = "create"
+	changeLabel string = "change"
+)
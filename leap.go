@@ -0,0 +1,21 @@
+package main
+
+import "regexp"
+
+// leapSeconds - time stamps whose seconds are 60, in the YYYYMMDD.HHMMSS and RFC3339 forms
+// the first group is everything before the seconds and the last is everything after them, less any fraction
+var leapSeconds = []*regexp.Regexp{
+	regexp.MustCompile(`^((?:(?:\d{2}|\d{4}|\d{8})\.)?\d{4})60()$`),
+	regexp.MustCompile(`^(.*T\d{2}:\d{2}:)60(?:\.\d+)?(.*)$`),
+}
+
+// replaceLeapSecond - return dt with a leap second of 60 replaced by 59, and whether it had one
+// Go, like file systems, has no way to represent the 61st second of a minute
+func replaceLeapSecond(dt string) (string, bool) {
+	for _, re := range leapSeconds {
+		if m := re.FindStringSubmatch(dt); m != nil {
+			return m[1] + "59" + m[2], true
+		}
+	}
+	return dt, false
+}
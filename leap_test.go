@@ -0,0 +1,43 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestReplaceLeapSecond(t *testing.T) {
+	tests := []struct {
+		dt, want string
+		found    bool
+	}{
+		{"20161231.235960", "20161231.235959", true},
+		{"2016-12-31T23:59:60Z", "2016-12-31T23:59:59Z", true},
+		{"2016-12-31T18:59:60.5-05:00", "2016-12-31T18:59:59-05:00", true},
+		{"235960", "235959", true},
+		{"20161231.235959", "20161231.235959", false},
+		{"20160601.120000", "20160601.120000", false},
+	}
+	for _, tt := range tests {
+		got, found := replaceLeapSecond(tt.dt)
+		if got != tt.want || found != tt.found {
+			t.Errorf("replaceLeapSecond(%s) = %s, %v, want %s, %v", tt.dt, got, found, tt.want, tt.found)
+		}
+	}
+}
+
+func TestCreateDateLeapSecond(t *testing.T) {
+	resetState(t)
+	if _, err := createDate("2016-12-31T23:59:60Z"); err == nil {
+		t.Errorf("a leap second was accepted without -lenient")
+	}
+
+	opts.lenient = true
+	want := time.Date(2016, 12, 31, 23, 59, 59, 999999999, time.UTC)
+	got, err := createDate("2016-12-31T23:59:60Z")
+	if err != nil || !got.Equal(want) {
+		t.Errorf("createDate with -lenient = %s, %v, want %s", got, err, want)
+	}
+	if got, err = createDate("2016-12-31T23:59:59Z"); err != nil || !got.Equal(want.Truncate(time.Second)) {
+		t.Errorf("createDate of an ordinary second = %s, %v", got, err)
+	}
+}
@@ -0,0 +1,46 @@
+package main
+
+import (
+	"sort"
+	"strings"
+	"time"
+)
+
+// localeLayouts - how dates and times are customarily written in a few locales, for -locale
+// Go has no locale support of its own, so this covers only the date order, separators, and clock
+var localeLayouts = map[string]string{
+	"en-us": "01/02/2006 3:04:05.999999999 PM MST",
+	"en-gb": "02/01/2006 15:04:05.999999999 MST",
+	"en-ca": "2006-01-02 3:04:05.999999999 PM MST",
+	"de-de": "02.01.2006 15:04:05.999999999 MST",
+	"fr-fr": "02/01/2006 15:04:05.999999999 MST",
+	"es-es": "02/01/2006 15:04:05.999999999 MST",
+	"it-it": "02/01/2006 15:04:05.999999999 MST",
+	"nl-nl": "02-01-2006 15:04:05.999999999 MST",
+	"sv-se": "2006-01-02 15:04:05.999999999 MST",
+	"ja-jp": "2006/01/02 15:04:05.999999999 MST",
+	"zh-cn": "2006/01/02 15:04:05.999999999 MST",
+}
+
+// localeLayout - return the layout for a locale such as en-GB or en_GB
+func localeLayout(locale string) (string, bool) {
+	layout, found := localeLayouts[strings.ToLower(strings.ReplaceAll(locale, "_", "-"))]
+	return layout, found
+}
+
+// localeNames - return the locales accepted by -locale, sorted
+func localeNames() []string {
+	names := make([]string, 0, len(localeLayouts))
+	for name := range localeLayouts {
+		lang, region, _ := strings.Cut(name, "-")
+		names = append(names, lang+"-"+strings.ToUpper(region))
+	}
+	sort.Strings(names)
+	return names
+}
+
+// formatLocale - return t as it is customarily written in opts.locale
+func formatLocale(t time.Time) string {
+	layout, _ := localeLayout(opts.locale)
+	return t.Format(layout)
+}
@@ -0,0 +1,33 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFormatLocale(t *testing.T) {
+	resetState(t)
+	tm := time.Date(2021, 3, 4, 15, 8, 7, 0, time.UTC)
+	tests := []struct {
+		locale, want string
+	}{
+		{"en-US", "03/04/2021 3:08:07 PM UTC"},
+		{"en_GB", "04/03/2021 15:08:07 UTC"},
+		{"de-DE", "04.03.2021 15:08:07 UTC"},
+		{"sv-SE", "2021-03-04 15:08:07 UTC"},
+	}
+	for _, tt := range tests {
+		opts.locale = tt.locale
+		if got := formatTime(tm); got != tt.want {
+			t.Errorf("-locale %s shows %q, want %q", tt.locale, got, tt.want)
+		}
+	}
+
+	if _, found := localeLayout("xx-XX"); found {
+		t.Errorf("an unknown locale was found")
+	}
+	names := localeNames()
+	if len(names) != len(localeLayouts) || names[0] != "de-DE" {
+		t.Errorf("localeNames = %q", names)
+	}
+}
@@ -0,0 +1,176 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// metadataExtractor - reads a time stamp embedded in a file's contents, such as a photo's capture time
+type metadataExtractor interface {
+	// Match returns true when the extractor understands files with this name
+	Match(file string) bool
+	// Extract returns the embedded time stamp, or false when the file does not have one
+	Extract(file string) (time.Time, bool, error)
+}
+
+// extractors - tried in order by metadataTimes, the first one matching a file is used
+var extractors = []metadataExtractor{
+	jpegExtractor{},
+}
+
+// metadataTimes - a timeResolver which sets a file's modify time to its embedded time stamp
+// files without an extractor or without embedded metadata are skipped with a warning
+func metadataTimes(rec fileRecord) (map[string]time.Time, error) {
+	for _, extractor := range extractors {
+		if !extractor.Match(rec.Name) {
+			continue
+		}
+		t, found, err := extractor.Extract(rec.Name)
+		if err != nil {
+			return nil, err
+		}
+		if !found {
+			break
+		}
+		return map[string]time.Time{"m": t}, nil
+	}
+	logError("Metadata Warning: %s: no embedded time stamp, skipping\n", rec.Name)
+	return nil, nil
+}
+
+// jpegExtractor - reads DateTimeOriginal from the EXIF data of a JPEG file
+type jpegExtractor struct{}
+
+// exif tags used by jpegExtractor
+const (
+	exifIFDPointer       uint16 = 0x8769
+	exifDateTimeOriginal uint16 = 0x9003
+	exifTypeASCII        uint16 = 2
+)
+
+// exifDateLayout - EXIF dates have no time zone and are taken to be local time
+const exifDateLayout string = "2006:01:02 15:04:05"
+
+func (jpegExtractor) Match(file string) bool {
+	ext := strings.ToLower(filepath.Ext(file))
+	return ext == ".jpg" || ext == ".jpeg"
+}
+
+func (jpegExtractor) Extract(file string) (time.Time, bool, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return time.Time{}, false, err
+	}
+	defer f.Close()
+	r := bufio.NewReader(f)
+
+	var soi [2]byte
+	if _, err := io.ReadFull(r, soi[:]); err != nil || soi != [2]byte{0xFF, 0xD8} {
+		return time.Time{}, false, nil
+	}
+
+	// walk the segments preceding the image data, looking for an APP1 segment holding EXIF
+	for {
+		b, err := r.ReadByte()
+		if err != nil {
+			return time.Time{}, false, nil
+		}
+		if b != 0xFF {
+			continue
+		}
+		marker, err := r.ReadByte()
+		if err != nil {
+			return time.Time{}, false, nil
+		}
+		switch {
+		case marker == 0xFF || marker == 0x00:
+			r.UnreadByte()
+			continue
+		case marker == 0xD9 || marker == 0xDA:
+			// end of image or start of scan: no more metadata segments
+			return time.Time{}, false, nil
+		case marker == 0x01 || (marker >= 0xD0 && marker <= 0xD7):
+			// markers without a length
+			continue
+		}
+
+		var length uint16
+		if err := binary.Read(r, binary.BigEndian, &length); err != nil || length < 2 {
+			return time.Time{}, false, nil
+		}
+		segment := make([]byte, length-2)
+		if _, err := io.ReadFull(r, segment); err != nil {
+			return time.Time{}, false, nil
+		}
+		if marker != 0xE1 || !bytes.HasPrefix(segment, []byte("Exif\x00\x00")) {
+			continue
+		}
+		return parseExifDate(segment[6:])
+	}
+}
+
+// parseExifDate - return DateTimeOriginal from a TIFF structured EXIF block
+func parseExifDate(tiff []byte) (time.Time, bool, error) {
+	if len(tiff) < 8 {
+		return time.Time{}, false, nil
+	}
+	var order binary.ByteOrder
+	switch string(tiff[:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return time.Time{}, false, nil
+	}
+	if order.Uint16(tiff[2:]) != 42 {
+		return time.Time{}, false, nil
+	}
+
+	entry, found := findIFDEntry(tiff, order, order.Uint32(tiff[4:]), exifIFDPointer)
+	if !found {
+		return time.Time{}, false, nil
+	}
+	entry, found = findIFDEntry(tiff, order, order.Uint32(entry[8:]), exifDateTimeOriginal)
+	if !found || order.Uint16(entry[2:]) != exifTypeASCII {
+		return time.Time{}, false, nil
+	}
+	count := order.Uint32(entry[4:])
+	offset := order.Uint32(entry[8:])
+	if count > 64 || uint64(offset)+uint64(count) > uint64(len(tiff)) {
+		return time.Time{}, false, nil
+	}
+	value := strings.TrimRight(string(tiff[offset:offset+count]), "\x00 ")
+	t, err := time.ParseInLocation(exifDateLayout, value, time.Local)
+	if err != nil {
+		return time.Time{}, false, fmt.Errorf("invalid EXIF DateTimeOriginal: %q", value)
+	}
+	return t, true, nil
+}
+
+// findIFDEntry - return the 12 byte entry for tag in the image file directory at offset
+func findIFDEntry(tiff []byte, order binary.ByteOrder, offset uint32, tag uint16) ([]byte, bool) {
+	if uint64(offset)+2 > uint64(len(tiff)) {
+		return nil, false
+	}
+	count := int(order.Uint16(tiff[offset:]))
+	start := int(offset) + 2
+	for i := 0; i < count; i++ {
+		pos := start + i*12
+		if pos+12 > len(tiff) {
+			return nil, false
+		}
+		entry := tiff[pos : pos+12]
+		if order.Uint16(entry) == tag {
+			return entry, true
+		}
+	}
+	return nil, false
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"bytes"
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// exifJPEG - return a minimal JPEG whose EXIF block, in the given byte order, has a DateTimeOriginal of date
+func exifJPEG(order binary.ByteOrder, date string) []byte {
+	tiff := &bytes.Buffer{}
+	if order == binary.LittleEndian {
+		tiff.WriteString("II")
+	} else {
+		tiff.WriteString("MM")
+	}
+	binary.Write(tiff, order, uint16(42))
+	binary.Write(tiff, order, uint32(8))
+	// IFD0 at 8 with one entry pointing to the EXIF IFD at 26
+	binary.Write(tiff, order, uint16(1))
+	for _, v := range []any{exifIFDPointer, uint16(4), uint32(1), uint32(26)} {
+		binary.Write(tiff, order, v)
+	}
+	binary.Write(tiff, order, uint32(0))
+	// EXIF IFD at 26 with DateTimeOriginal stored at 44
+	value := append([]byte(date), 0)
+	binary.Write(tiff, order, uint16(1))
+	for _, v := range []any{exifDateTimeOriginal, exifTypeASCII, uint32(len(value)), uint32(44)} {
+		binary.Write(tiff, order, v)
+	}
+	binary.Write(tiff, order, uint32(0))
+	tiff.Write(value)
+
+	segment := append([]byte("Exif\x00\x00"), tiff.Bytes()...)
+	jpeg := &bytes.Buffer{}
+	jpeg.Write([]byte{0xFF, 0xD8})
+	// an APP0 segment to skip over before the EXIF one
+	jpeg.Write([]byte{0xFF, 0xE0, 0x00, 0x04, 0x00, 0x00})
+	jpeg.Write([]byte{0xFF, 0xE1})
+	binary.Write(jpeg, binary.BigEndian, uint16(len(segment)+2))
+	jpeg.Write(segment)
+	jpeg.Write([]byte{0xFF, 0xDA, 0x00, 0x02, 0xFF, 0xD9})
+	return jpeg.Bytes()
+}
+
+func TestJPEGExtractor(t *testing.T) {
+	want := time.Date(2021, 3, 29, 9, 8, 7, 0, time.Local)
+	dir := t.TempDir()
+	for _, order := range []binary.ByteOrder{binary.LittleEndian, binary.BigEndian} {
+		file := filepath.Join(dir, order.String()+".jpg")
+		if err := os.WriteFile(file, exifJPEG(order, "2021:03:29 09:08:07"), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if !(jpegExtractor{}).Match(file) {
+			t.Errorf("%s is not matched", file)
+		}
+		got, found, err := jpegExtractor{}.Extract(file)
+		if err != nil || !found || !got.Equal(want) {
+			t.Errorf("%s: Extract = %s, %v, %v, want %s", order, got, found, err, want)
+		}
+	}
+
+	plain := filepath.Join(dir, "plain.jpg")
+	if err := os.WriteFile(plain, []byte{0xFF, 0xD8, 0xFF, 0xDA, 0x00, 0x02, 0xFF, 0xD9}, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, found, err := (jpegExtractor{}).Extract(plain); found || err != nil {
+		t.Errorf("a JPEG without EXIF returned %v, %v", found, err)
+	}
+
+	bad := filepath.Join(dir, "bad.jpg")
+	if err := os.WriteFile(bad, exifJPEG(binary.LittleEndian, "2021-03-29 09:08:07"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := (jpegExtractor{}).Extract(bad); err == nil {
+		t.Errorf("an invalid DateTimeOriginal was accepted")
+	}
+}
+
+func TestParseExifDateTruncated(t *testing.T) {
+	// skip the SOI, APP0, APP1 marker and length, and Exif header
+	tiff := exifJPEG(binary.BigEndian, "2021:03:29 09:08:07")[18:]
+	if _, found, err := parseExifDate(tiff); !found || err != nil {
+		t.Fatalf("the complete block was not parsed: %v", err)
+	}
+	for n := 0; n < 64; n++ {
+		if _, found, _ := parseExifDate(tiff[:n]); found {
+			t.Errorf("a block truncated to %d bytes was parsed", n)
+		}
+	}
+}
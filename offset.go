@@ -0,0 +1,54 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// dayOffsets - a count of days in an offset, such as 7d or 1.5d, which time.ParseDuration does not accept
+var dayOffsets = regexp.MustCompile(`(\d+(?:\.\d+)?)d`)
+
+// looksLikeOffset - a sign followed by a number and a unit, used to explain a malformed offset
+var looksLikeOffset = regexp.MustCompile(`^[+-]\d+(?:\.\d+)?[a-zµ]`)
+
+// parseOffset - return the duration of a signed offset such as -2h, +30m, -7d, or -1.5d
+func parseOffset(s string) (time.Duration, error) {
+	if len(s) < 2 || (s[0] != '+' && s[0] != '-') {
+		return 0, fmt.Errorf("offset must start with + or -: %s", s)
+	}
+	days := dayOffsets.ReplaceAllStringFunc(s, func(d string) string {
+		n, _ := strconv.ParseFloat(strings.TrimSuffix(d, "d"), 64)
+		return strconv.FormatFloat(n*24, 'f', -1, 64) + "h"
+	})
+	return time.ParseDuration(days)
+}
+
+// splitOffset - return the time stamp and trailing offset of an expression such as now-2h
+// found is false when value does not end with an offset, as with RFC3339 zone offsets like -05:00
+func splitOffset(value string) (base string, offset time.Duration, found bool, err error) {
+	i := strings.LastIndexAny(value, "+-")
+	if i <= 0 {
+		return value, 0, false, nil
+	}
+	offset, err = parseOffset(value[i:])
+	if err != nil {
+		if looksLikeOffset.MatchString(value[i:]) {
+			return value, 0, false, fmt.Errorf("invalid offset %s in %s: use units of ns, us, ms, s, m, h, or d", value[i:], value)
+		}
+		return value, 0, false, nil
+	}
+	return value[:i], offset, true, nil
+}
+
+// sameWeekday - return shifted moved by up to three days to the nearest day falling on the same weekday as orig
+// calendar days are used rather than 24 hours so the time of day is kept across daylight saving time changes
+func sameWeekday(orig, shifted time.Time) time.Time {
+	days := (int(orig.Weekday()) - int(shifted.Weekday()) + 7) % 7
+	if days > 3 {
+		days -= 7
+	}
+	return shifted.AddDate(0, 0, days)
+}
@@ -0,0 +1,138 @@
+package main
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestOffsetFrom(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Date(2025, 1, 10, 0, 0, 0, 0, time.UTC)
+	ref := writeFile(t, filepath.Join(dir, "ref.txt"), "", now.Add(-50*time.Hour))
+	mtime := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	file := writeFile(t, filepath.Join(dir, "a.txt"), "", mtime)
+
+	if _, stderr, code := runGostat(t, "-now", now.Format(time.RFC3339), "-offset-from", ref, file); code != 0 {
+		t.Fatalf("-offset-from exited %d: %s", code, stderr)
+	}
+	rec, err := newFileRecord(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := mtime.Add(-50 * time.Hour)
+	if !rec.Mtime.Equal(want) || !rec.Atime.Equal(want) {
+		t.Errorf("-offset-from gave %s and %s, want both %s", rec.Mtime, rec.Atime, want)
+	}
+	if rec, err = newFileRecord(ref); err != nil || !rec.Mtime.Equal(now.Add(-50*time.Hour)) {
+		t.Errorf("the reference file was changed to %s, %v", rec.Mtime, err)
+	}
+}
+
+func TestParseOffset(t *testing.T) {
+	tests := []struct {
+		s    string
+		want time.Duration
+	}{
+		{"-2h", -2 * time.Hour},
+		{"+30m", 30 * time.Minute},
+		{"-7d", -7 * 24 * time.Hour},
+		{"-1.5d", -36 * time.Hour},
+		{"+0.25d", 6 * time.Hour},
+		{"+1d12h", 36 * time.Hour},
+		{"-1.5h", -90 * time.Minute},
+	}
+	for _, tt := range tests {
+		got, err := parseOffset(tt.s)
+		if err != nil || got != tt.want {
+			t.Errorf("parseOffset(%s) = %s, %v, want %s", tt.s, got, err, tt.want)
+		}
+	}
+	for _, s := range []string{"2h", "-", "-1.d", "-2x"} {
+		if got, err := parseOffset(s); err == nil {
+			t.Errorf("parseOffset(%s) = %s, want an error", s, got)
+		}
+	}
+}
+
+func TestSplitOffset(t *testing.T) {
+	tests := []struct {
+		value  string
+		base   string
+		offset time.Duration
+		found  bool
+	}{
+		{"now-1.5d", "now", -36 * time.Hour, true},
+		{"now-2h", "now", -2 * time.Hour, true},
+		{"20250101.000000+30m", "20250101.000000", 30 * time.Minute, true},
+		{"@som+1d", "@som", 24 * time.Hour, true},
+		{"2025-01-01T00:00:00-05:00", "2025-01-01T00:00:00-05:00", 0, false},
+		{"2025-01-01T00:00:00+05:30", "2025-01-01T00:00:00+05:30", 0, false},
+		{"20250101.000000", "20250101.000000", 0, false},
+	}
+	for _, tt := range tests {
+		base, offset, found, err := splitOffset(tt.value)
+		if err != nil || base != tt.base || offset != tt.offset || found != tt.found {
+			t.Errorf("splitOffset(%s) = %s, %s, %v, %v, want %s, %s, %v", tt.value, base, offset, found, err, tt.base, tt.offset, tt.found)
+		}
+	}
+	if _, _, _, err := splitOffset("now-2y"); err == nil {
+		t.Errorf("splitOffset accepted an offset in years")
+	}
+}
+
+func TestSameWeekday(t *testing.T) {
+	// a Wednesday
+	orig := time.Date(2024, 1, 3, 10, 0, 0, 0, time.UTC)
+	tests := []struct {
+		shifted time.Time
+		want    time.Time
+	}{
+		{orig.AddDate(0, 0, 7), orig.AddDate(0, 0, 7)},
+		{orig.AddDate(0, 0, 1), orig},
+		{orig.AddDate(0, 0, -1), orig},
+		{orig.AddDate(0, 0, 3), orig},
+		{orig.AddDate(0, 0, 4), orig.AddDate(0, 0, 7)},
+		{orig.Add(30*24*time.Hour + 5*time.Minute), orig.AddDate(0, 0, 28).Add(5 * time.Minute)},
+	}
+	for _, tt := range tests {
+		got := sameWeekday(orig, tt.shifted)
+		if !got.Equal(tt.want) || got.Weekday() != orig.Weekday() {
+			t.Errorf("sameWeekday(%s) = %s, want %s", tt.shifted, got, tt.want)
+		}
+	}
+
+	// moving from a Saturday before daylight saving time to the Monday after keeps the time of day
+	ny, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skip(err)
+	}
+	monday := time.Date(2024, 3, 4, 12, 0, 0, 0, ny)
+	saturday := time.Date(2024, 3, 9, 12, 0, 0, 0, ny)
+	if got, want := sameWeekday(monday, saturday), time.Date(2024, 3, 11, 12, 0, 0, 0, ny); !got.Equal(want) {
+		t.Errorf("sameWeekday across daylight saving time = %s, want %s", got, want)
+	}
+}
+
+func TestOffsetSameWeekday(t *testing.T) {
+	resetState(t)
+	opts.sameWeekday = true
+	mtime := time.Date(2024, 1, 3, 10, 0, 0, 0, time.UTC)
+	atime := mtime.AddDate(0, 0, 1)
+	got, err := offsetTimes(-30 * 24 * time.Hour)(fileRecord{Name: "a.txt", Mtime: mtime, Atime: atime})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := mtime.AddDate(0, 0, -28); !got["m"].Equal(want) {
+		t.Errorf("-offset -30d -same-weekday gave mtime %s, want %s", got["m"], want)
+	}
+	if want := atime.AddDate(0, 0, -28); !got["a"].Equal(want) {
+		t.Errorf("-offset -30d -same-weekday gave atime %s, want %s", got["a"], want)
+	}
+
+	file := tempFile(t, "a.txt", "", mtime)
+	if _, stderr, code := runGostat(t, "-same-weekday", file); code == 0 || !strings.Contains(stderr, "-same-weekday requires") {
+		t.Errorf("-same-weekday without an offset exited %d: %s", code, stderr)
+	}
+}
@@ -0,0 +1,45 @@
+//go:build !windows
+
+package main
+
+import (
+	"os"
+	"os/user"
+	"strconv"
+	"syscall"
+)
+
+// lookupUser - return the id of a user given by name or number
+// a number is used as is, since files can be owned by ids missing from the user database, such as those of deleted users
+func lookupUser(name string) (int64, error) {
+	if id, err := strconv.ParseUint(name, 10, 32); err == nil {
+		return int64(id), nil
+	}
+	u, err := user.Lookup(name)
+	if err != nil {
+		return -1, user.UnknownUserError(name)
+	}
+	return strconv.ParseInt(u.Uid, 10, 64)
+}
+
+// lookupGroup - return the id of a group given by name or number
+// a number is used as is, as with lookupUser
+func lookupGroup(name string) (int64, error) {
+	if id, err := strconv.ParseUint(name, 10, 32); err == nil {
+		return int64(id), nil
+	}
+	g, err := user.LookupGroup(name)
+	if err != nil {
+		return -1, user.UnknownGroupError(name)
+	}
+	return strconv.ParseInt(g.Gid, 10, 64)
+}
+
+// fileOwner - return the user and group ids of a file, or -1 when they are unknown
+func fileOwner(fi os.FileInfo) (uid, gid int64) {
+	st, ok := fi.Sys().(*syscall.Stat_t)
+	if !ok {
+		return -1, -1
+	}
+	return int64(st.Uid), int64(st.Gid)
+}
@@ -0,0 +1,69 @@
+//go:build !windows
+
+package main
+
+import (
+	"os"
+	"os/user"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestOwnerFilters(t *testing.T) {
+	file := tempFile(t, "a.txt", "", time.Now())
+	uid, gid := strconv.Itoa(os.Getuid()), strconv.Itoa(os.Getgid())
+	owners := [][]string{{"-user", uid}, {"-group", gid}, {"-user", uid, "-group", gid}}
+	if u, err := user.Current(); err == nil {
+		owners = append(owners, []string{"-user", u.Username})
+	}
+	for _, args := range owners {
+		out, stderr, code := runGostat(t, append(args, file)...)
+		if got := shownNames(out); code != 0 || !reflect.DeepEqual(got, []string{file}) {
+			t.Errorf("%q exited %d showing %q, want %s: %s", args, code, got, file, stderr)
+		}
+	}
+
+	resetState(t)
+	rec, err := newFileRecord(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	opts.uid = int64(os.Getuid()) + 1
+	if selected(rec) {
+		t.Errorf("a file owned by uid %d was selected by uid %d", os.Getuid(), opts.uid)
+	}
+	opts.uid, opts.gid = -1, int64(os.Getgid())+1
+	if selected(rec) {
+		t.Errorf("a file of gid %d was selected by gid %d", os.Getgid(), opts.gid)
+	}
+
+	if _, err := lookupUser("no-such-user-gostat"); err == nil {
+		t.Errorf("an unknown user was found")
+	}
+}
+
+func TestOwnerUnknownId(t *testing.T) {
+	// an id which is in no user or group database, as for files of a deleted user
+	const unknown = "4000000000"
+	if _, err := user.LookupId(unknown); err == nil {
+		t.Skipf("uid %s is in the user database", unknown)
+	}
+	if uid, err := lookupUser(unknown); err != nil || uid != 4000000000 {
+		t.Errorf("lookupUser(%s) = %d, %v", unknown, uid, err)
+	}
+	if gid, err := lookupGroup(unknown); err != nil || gid != 4000000000 {
+		t.Errorf("lookupGroup(%s) = %d, %v", unknown, gid, err)
+	}
+
+	file := tempFile(t, "a.txt", "", time.Now())
+	out, stderr, _ := runGostat(t, "-user", unknown, file)
+	if len(shownNames(out)) > 0 || strings.Contains(stderr, "unknown user") || !strings.Contains(stderr, "did not match any files") {
+		t.Errorf("-user %s showed %q, want no matching files: %s", unknown, shownNames(out), stderr)
+	}
+	if _, err := lookupGroup("no-such-group-gostat"); err == nil {
+		t.Errorf("an unknown group was found")
+	}
+}
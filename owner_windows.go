@@ -0,0 +1,24 @@
+package main
+
+import (
+	"errors"
+	"os"
+)
+
+// errOwnerUnavailable - Windows files have security descriptors rather than user and group ids
+var errOwnerUnavailable = errors.New("-user and -group are not supported on Windows")
+
+// lookupUser - file ownership filters are not supported on Windows
+func lookupUser(name string) (int64, error) {
+	return -1, errOwnerUnavailable
+}
+
+// lookupGroup - file ownership filters are not supported on Windows
+func lookupGroup(name string) (int64, error) {
+	return -1, errOwnerUnavailable
+}
+
+// fileOwner - file ownership is not available on Windows
+func fileOwner(fi os.FileInfo) (uid, gid int64) {
+	return -1, -1
+}
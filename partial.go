@@ -0,0 +1,34 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"time"
+)
+
+// validPartial - an optional date of DD, MMDD, or YYYYMMDD followed by a dot, then a time of HHMM or HHMMSS
+var validPartial = regexp.MustCompile(`^(?:(\d{2}|\d{4}|\d{8})\.)?(\d{4}|\d{6})$`)
+
+// parsePartialDate - return the time for a shortened YYYYMMDD.HHMMSS time stamp
+// leading date components which are left out are taken from today, so 1230 is today at 12:30,
+// 15.0930 is the 15th of this month, and 0115.093000 is January 15th of this year;
+// seconds which are left out are zero
+// a date without a time, such as 20250115, is ambiguous with HHMMSS and is rejected
+func parsePartialDate(dt string) (time.Time, error) {
+	m := validPartial.FindStringSubmatch(dt)
+	if m == nil {
+		return time.Time{}, fmt.Errorf("invalid time stamp: %s", dt)
+	}
+	datePart, timePart := m[1], m[2]
+
+	full := startTime.Format("20060102")
+	full = full[:8-len(datePart)] + datePart
+	if len(timePart) == 4 {
+		timePart += "00"
+	}
+	t, err := time.ParseInLocation("20060102.150405", full+"."+timePart, time.Local)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid time stamp: %s", dt)
+	}
+	return t, nil
+}
@@ -0,0 +1,34 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParsePartialDate(t *testing.T) {
+	resetState(t)
+	startTime = time.Date(2025, 6, 20, 8, 0, 0, 0, time.Local)
+
+	tests := []struct {
+		dt   string
+		want time.Time
+	}{
+		{"1230", time.Date(2025, 6, 20, 12, 30, 0, 0, time.Local)},
+		{"123045", time.Date(2025, 6, 20, 12, 30, 45, 0, time.Local)},
+		{"15.0930", time.Date(2025, 6, 15, 9, 30, 0, 0, time.Local)},
+		{"0115.093000", time.Date(2025, 1, 15, 9, 30, 0, 0, time.Local)},
+		{"20240229.235959", time.Date(2024, 2, 29, 23, 59, 59, 0, time.Local)},
+	}
+	for _, tt := range tests {
+		got, err := parsePartialDate(tt.dt)
+		if err != nil || !got.Equal(tt.want) {
+			t.Errorf("parsePartialDate(%q) = %s, %v, want %s", tt.dt, got, err, tt.want)
+		}
+	}
+
+	for _, dt := range []string{"20250115", "123", "1.1230", "0230.1200", "2460", "15.093", "abcd"} {
+		if got, err := parsePartialDate(dt); err == nil {
+			t.Errorf("parsePartialDate(%q) = %s, want an error", dt, got)
+		}
+	}
+}
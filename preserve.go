@@ -0,0 +1,91 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"os/signal"
+	"strings"
+)
+
+// preserveAround - run a command, then put back the access and modify times matched files had before it ran
+// only files whose times the command changed are restored and displayed; the command's exit code is returned,
+// or 1 when it was ended by a signal, such as a Ctrl-C which gostat passes on to it
+func preserveAround(allFiles []string, cmdLine string) (int, error) {
+	cmdArgs, err := splitCommand(cmdLine)
+	if err != nil {
+		return 0, err
+	}
+
+	saved := make(map[string]fileRecord)
+	var order []string
+	for _, file := range allFiles {
+		rec, err := statFile(file)
+		if err != nil {
+			logError("Lstat Error: %s\n", err)
+			continue
+		}
+		if !selected(rec) {
+			continue
+		}
+		saved[file] = rec
+		order = append(order, file)
+	}
+
+	cmd := exec.Command(cmdArgs[0], cmdArgs[1:]...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	// a Ctrl-C is passed on to the command instead of ending gostat, so the times are still restored
+	sigs := make(chan os.Signal, 1)
+	signal.Notify(sigs, os.Interrupt)
+	if err := cmd.Start(); err != nil {
+		signal.Stop(sigs)
+		return 0, fmt.Errorf("command failed: %s: %w", strings.Join(cmdArgs, " "), err)
+	}
+	go func() {
+		for sig := range sigs {
+			cmd.Process.Signal(sig)
+		}
+	}()
+	waitErr := cmd.Wait()
+	signal.Stop(sigs)
+	close(sigs)
+
+	for _, file := range order {
+		before := saved[file]
+		after, err := statFile(file)
+		if err != nil {
+			// the command removed or renamed it
+			logError("Lstat Error: %s\n", err)
+			continue
+		}
+		if after.Atime.Equal(before.Atime) && after.Mtime.Equal(before.Mtime) {
+			continue
+		}
+		if err = chtimes(file, before.Atime, before.Mtime); err != nil {
+			logError("os.Chtimes Error: %s\n", err)
+			continue
+		}
+		rec, err := statFile(file)
+		if err != nil {
+			logError("Lstat Error: %s\n", err)
+			continue
+		}
+		displayRecord(rec, after.times())
+	}
+
+	if waitErr != nil {
+		var exitErr *exec.ExitError
+		if !errors.As(waitErr, &exitErr) {
+			return 0, fmt.Errorf("command failed: %s: %w", strings.Join(cmdArgs, " "), waitErr)
+		}
+		if code := exitErr.ExitCode(); code > 0 {
+			return code, nil
+		}
+		// ended by a signal
+		return 1, nil
+	}
+	return 0, nil
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestPreserveAround(t *testing.T) {
+	touch, err := exec.LookPath("touch")
+	if err != nil {
+		t.Skip("touch is unavailable")
+	}
+	dir := t.TempDir()
+	mtime := time.Date(2021, 3, 29, 9, 8, 7, 0, time.UTC)
+	touched := writeFile(t, filepath.Join(dir, "touched.txt"), "", mtime)
+	untouched := writeFile(t, filepath.Join(dir, "untouched.txt"), "", mtime)
+
+	out, stderr, code := runGostat(t, "-preserve-around", touch+" "+shellQuote(touched), touched, untouched)
+	if code != 0 {
+		t.Fatalf("-preserve-around exited %d: %s", code, stderr)
+	}
+	for _, file := range []string{touched, untouched} {
+		rec, err := newFileRecord(file)
+		if err != nil || !rec.Mtime.Equal(mtime) || !rec.Atime.Equal(mtime) {
+			t.Errorf("%s has mtime %s and atime %s, %v, want both %s", file, rec.Mtime, rec.Atime, err, mtime)
+		}
+	}
+	// only files whose times the command changed are shown
+	if got := shownNames(out); !reflect.DeepEqual(got, []string{touched}) {
+		t.Errorf("-preserve-around showed %q, want only %s", got, touched)
+	}
+
+	_, _, code = runGostat(t, "-preserve-around", "sh -c 'exit 3'", untouched)
+	if code != 3 {
+		t.Errorf("-preserve-around exited %d, want the command's exit code 3", code)
+	}
+}
+
+func TestPreserveAroundInterrupt(t *testing.T) {
+	sh, err := exec.LookPath("sh")
+	if err != nil {
+		t.Skip("sh is unavailable")
+	}
+	dir := t.TempDir()
+	mtime := time.Date(2021, 3, 29, 9, 8, 7, 0, time.UTC)
+	file := writeFile(t, filepath.Join(dir, "a.txt"), "", mtime)
+
+	// the command changes the file, then interrupts gostat, its parent, which passes the signal back to it
+	script := "echo changed > " + shellQuote(file) + "; kill -INT $PPID; exec sleep 10"
+	start := time.Now()
+	_, stderr, code := runGostat(t, "-preserve-around", sh+" -c "+shellQuote(script), file)
+	if code != 1 {
+		t.Errorf("-preserve-around exited %d, want 1 for a command ended by a signal: %s", code, stderr)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("the interrupt was not passed on to the command, which ran for %s", elapsed)
+	}
+	if rec, err := newFileRecord(file); err != nil || !rec.Mtime.Equal(mtime) || !rec.Atime.Equal(mtime) {
+		t.Errorf("after an interrupt %s has mtime %s and atime %s, %v, want both restored to %s", file, rec.Mtime, rec.Atime, err, mtime)
+	}
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// promEscaper - escapes label values for the Prometheus text exposition format
+var promEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// promSeconds - return t as fractional seconds since the Unix epoch
+func promSeconds(t time.Time) string {
+	return strconv.FormatFloat(float64(t.UnixNano())/float64(time.Second), 'f', -1, 64)
+}
+
+// writePrometheus - output each file's times and size as metrics in the Prometheus text exposition format,
+// suitable for the node_exporter textfile collector
+func writePrometheus() {
+	type metric struct {
+		name  string
+		help  string
+		value func(rec fileRecord) (string, bool)
+	}
+	timeMetric := func(field string) func(rec fileRecord) (string, bool) {
+		return func(rec fileRecord) (string, bool) {
+			t, found := rec.times()[field]
+			return promSeconds(t), found
+		}
+	}
+	metrics := []metric{
+		{"gostat_file_mtime_seconds", "Last modification time of the file, in seconds since the Unix epoch.", timeMetric("m")},
+		{"gostat_file_atime_seconds", "Last access time of the file, in seconds since the Unix epoch.", timeMetric("a")},
+		{"gostat_file_btime_seconds", "Birth (creation) time of the file, in seconds since the Unix epoch.", timeMetric("b")},
+		{"gostat_file_ctime_seconds", "Last metadata change time of the file, in seconds since the Unix epoch.", timeMetric("c")},
+	}
+	if !opts.noSize {
+		metrics = append(metrics, metric{"gostat_file_size_bytes", "Size of the file in bytes.", func(rec fileRecord) (string, bool) {
+			return strconv.FormatInt(rec.Size, 10), true
+		}})
+	}
+
+	for _, m := range metrics {
+		var lines []string
+		for _, rec := range records {
+			if value, found := m.value(rec); found {
+				lines = append(lines, fmt.Sprintf("%s{path=\"%s\"} %s", m.name, promEscaper.Replace(rec.Name), value))
+			}
+		}
+		if len(lines) == 0 {
+			continue
+		}
+		fmt.Fprintf(output, "# HELP %s %s\n", m.name, m.help)
+		fmt.Fprintf(output, "# TYPE %s gauge\n", m.name)
+		for _, line := range lines {
+			fmt.Fprintln(output, line)
+		}
+	}
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// promSample - a sample line of the text exposition format with a single path label
+var promSample = regexp.MustCompile(`^([a-zA-Z_:][a-zA-Z0-9_:]*)\{path="((?:[^"\\\n]|\\[\\"n])*)"\} (\S+)$`)
+
+func TestWritePrometheus(t *testing.T) {
+	buf := resetState(t)
+	mtime := time.Date(2021, 3, 29, 9, 8, 7, 500000000, time.UTC)
+	btime := mtime.Add(-time.Hour)
+	odd := "dir\\\"quoted\"\nname.txt"
+	records = []fileRecord{
+		{Name: "a.txt", Size: 42, Mtime: mtime, Atime: mtime, Btime: &btime},
+		{Name: odd, Size: 0, Mtime: mtime, Atime: mtime},
+	}
+
+	writePrometheus()
+	unescape := strings.NewReplacer(`\\`, `\`, `\"`, `"`, `\n`, "\n")
+	typed := make(map[string]bool)
+	samples := make(map[string]map[string]float64)
+	for _, line := range strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n") {
+		if name, found := strings.CutPrefix(line, "# HELP "); found {
+			if len(strings.Fields(name)) < 2 {
+				t.Errorf("HELP without text: %q", line)
+			}
+			continue
+		}
+		if name, found := strings.CutPrefix(line, "# TYPE "); found {
+			name, kind, _ := strings.Cut(name, " ")
+			if kind != "gauge" || typed[name] {
+				t.Errorf("bad or repeated TYPE line: %q", line)
+			}
+			typed[name] = true
+			continue
+		}
+		m := promSample.FindStringSubmatch(line)
+		if m == nil {
+			t.Errorf("invalid sample line: %q", line)
+			continue
+		}
+		if !typed[m[1]] {
+			t.Errorf("sample before its TYPE line: %q", line)
+		}
+		value, err := strconv.ParseFloat(m[3], 64)
+		if err != nil {
+			t.Errorf("invalid value in %q: %s", line, err)
+		}
+		if samples[m[1]] == nil {
+			samples[m[1]] = make(map[string]float64)
+		}
+		samples[m[1]][unescape.Replace(m[2])] = value
+	}
+
+	if got := samples["gostat_file_mtime_seconds"][odd]; got != 1617008887.5 {
+		t.Errorf("mtime of %q = %f", odd, got)
+	}
+	if got := samples["gostat_file_size_bytes"]["a.txt"]; got != 42 {
+		t.Errorf("size of a.txt = %f, want 42", got)
+	}
+	if got := samples["gostat_file_btime_seconds"]; len(got) != 1 || got["a.txt"] != 1617005287.5 {
+		t.Errorf("btime samples = %v, want only a.txt", got)
+	}
+	if typed["gostat_file_ctime_seconds"] {
+		t.Errorf("a metric with no samples was output")
+	}
+}
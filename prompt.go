@@ -0,0 +1,44 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// answer - a response to a confirmation prompt
+type answer int
+
+const (
+	answerNo answer = iota
+	answerYes
+	answerAll
+)
+
+// stdinIsTerminal - return true when STDIN is an interactive terminal instead of a pipe or file
+func stdinIsTerminal() bool {
+	fi, err := os.Stdin.Stat()
+	if err != nil {
+		return false
+	}
+	return fi.Mode()&os.ModeCharDevice != 0
+}
+
+// confirm - write question to w and read a y(es), n(o), or a(ll) response from r
+// anything other than yes or all, including EOF, is treated as no
+func confirm(r *bufio.Reader, w io.Writer, question string) answer {
+	fmt.Fprintf(w, "%s [y/N/a] ", question)
+	line, err := r.ReadString('\n')
+	if err != nil && (err != io.EOF || len(line) == 0) {
+		return answerNo
+	}
+	switch strings.ToLower(strings.TrimSpace(line)) {
+	case "y", "yes":
+		return answerYes
+	case "a", "all":
+		return answerAll
+	}
+	return answerNo
+}
@@ -0,0 +1,35 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestConfirm(t *testing.T) {
+	tests := []struct {
+		input string
+		want  answer
+	}{
+		{"y\n", answerYes},
+		{"YES\n", answerYes},
+		{"n\n", answerNo},
+		{"a\n", answerAll},
+		{" all \n", answerAll},
+		{"\n", answerNo},
+		{"maybe\n", answerNo},
+		{"y", answerYes},
+		{"", answerNo},
+	}
+	for _, tt := range tests {
+		var w bytes.Buffer
+		got := confirm(bufio.NewReader(strings.NewReader(tt.input)), &w, "set mtime?")
+		if got != tt.want {
+			t.Errorf("confirm(%q) = %d, want %d", tt.input, got, tt.want)
+		}
+		if w.String() != "set mtime? [y/N/a] " {
+			t.Errorf("confirm wrote the prompt %q", w.String())
+		}
+	}
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// samePath - compare paths the way the file system does, ignoring case on Windows
+func samePath(a, b string) bool {
+	if runtime.GOOS == "windows" {
+		return strings.EqualFold(a, b)
+	}
+	return a == b
+}
+
+// isProtected - return the entry of protectedPaths which file resolves to or lies beneath, if any
+// both the path as given and with symbolic links resolved are checked, since on macOS /etc links to /private/etc
+func isProtected(file string) (string, bool) {
+	abs, err := filepath.Abs(file)
+	if err != nil {
+		return "", false
+	}
+	candidates := []string{abs}
+	if real, err := filepath.EvalSymlinks(abs); err == nil && real != abs {
+		candidates = append(candidates, real)
+	}
+	for _, resolved := range candidates {
+		if dir, found := protectedDir(resolved); found {
+			return dir, true
+		}
+	}
+	return "", false
+}
+
+// protectedDir - return the entry of protectedPaths which the absolute path resolved is or lies beneath, if any
+func protectedDir(resolved string) (string, bool) {
+	for _, dir := range protectedPaths {
+		if samePath(resolved, dir) {
+			return dir, true
+		}
+		// a volume root protects only itself, not every file on it
+		if filepath.Dir(dir) == dir {
+			continue
+		}
+		if len(resolved) > len(dir) && samePath(resolved[:len(dir)], dir) && resolved[len(dir)] == filepath.Separator {
+			return dir, true
+		}
+	}
+	return "", false
+}
+
+// refuseProtected - return the first file that is, or is beneath, a protected system directory
+// and how many such files there are
+func refuseProtected(files []string) (string, string, int) {
+	var first, firstDir string
+	count := 0
+	for _, file := range files {
+		if dir, found := isProtected(file); found {
+			if count == 0 {
+				first, firstDir = file, dir
+			}
+			count += 1
+		}
+	}
+	return first, firstDir, count
+}
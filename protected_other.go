@@ -0,0 +1,22 @@
+//go:build !windows
+
+package main
+
+// protectedPaths - system directories whose times are not changed without -force
+// the root directory is protected by itself, the others along with everything beneath them
+var protectedPaths = []string{
+	"/",
+	"/bin",
+	"/boot",
+	"/dev",
+	"/etc",
+	"/lib",
+	"/lib64",
+	"/proc",
+	"/sbin",
+	"/sys",
+	"/usr",
+	"/System",
+	"/Library",
+	"/Applications",
+}
@@ -0,0 +1,54 @@
+//go:build !windows
+
+package main
+
+import (
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestIsProtected(t *testing.T) {
+	tests := []struct {
+		file    string
+		wantDir string
+		want    bool
+	}{
+		{"/etc", "/etc", true},
+		{"/etc/hosts", "/etc", true},
+		{"/usr/local/bin/x", "/usr", true},
+		{"/", "/", true},
+		{"/etcetera", "", false},
+		{"/home/user/etc", "", false},
+		{"/opt/x", "", false},
+	}
+	for _, tt := range tests {
+		dir, found := isProtected(tt.file)
+		if dir != tt.wantDir || found != tt.want {
+			t.Errorf("isProtected(%s) = %s, %v, want %s, %v", tt.file, dir, found, tt.wantDir, tt.want)
+		}
+	}
+
+	file, dir, count := refuseProtected([]string{"/opt/a", "/etc/b", "/usr/c"})
+	if file != "/etc/b" || dir != "/etc" || count != 2 {
+		t.Errorf("refuseProtected = %s, %s, %d, want /etc/b, /etc, 2", file, dir, count)
+	}
+}
+
+func TestRefuseProtected(t *testing.T) {
+	const file = "/etc/passwd"
+	rec, err := newFileRecord(file)
+	if err != nil {
+		t.Skip(err)
+	}
+	// the time given is the one it already has, so nothing changes even if it were not refused
+	other := tempFile(t, "a.txt", "", time.Now())
+	_, stderr, code := runGostat(t, "-m", rec.Mtime.Format(time.RFC3339Nano), other, file)
+	if code == 0 || !strings.Contains(stderr, "refusing to change 1 file(s) in protected system directories, such as /etc/passwd in /etc") {
+		t.Errorf("setting %s exited %d: %s", file, code, stderr)
+	}
+	if after, err := os.Stat(other); err != nil || after.ModTime().Equal(rec.Mtime) {
+		t.Errorf("other files were changed before the refusal")
+	}
+}
@@ -0,0 +1,11 @@
+package main
+
+// protectedPaths - system directories whose times are not changed without -force
+// the root directory is protected by itself, the others along with everything beneath them
+var protectedPaths = []string{
+	`C:\`,
+	`C:\Windows`,
+	`C:\Program Files`,
+	`C:\Program Files (x86)`,
+	`C:\ProgramData`,
+}
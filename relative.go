@@ -0,0 +1,41 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// anchorDirEnv - the directory -relative-to looks in for a marker file given without a directory
+const anchorDirEnv string = "GOSTAT_ANCHOR_DIR"
+
+// anchorFilePath - return the path of a -relative-to marker file
+// a bare name such as deploy is looked for in $GOSTAT_ANCHOR_DIR when that is set
+func anchorFilePath(name string) string {
+	dir := os.Getenv(anchorDirEnv)
+	if len(dir) == 0 || filepath.Base(name) != name {
+		return name
+	}
+	return filepath.Join(dir, name)
+}
+
+// relativeTime - return the modify time of a -relative-to marker file plus an optional trailing offset, as in deploy+10m
+// the marker is read once, so every file is given the same time; returns the marker's path as well
+func relativeTime(value string) (time.Time, string, error) {
+	base, offset, found, offsetErr := splitOffset(value)
+	if found {
+		path := anchorFilePath(base)
+		if rec, err := statFile(path); err == nil {
+			return rec.Mtime.Add(offset), path, nil
+		}
+	}
+	path := anchorFilePath(value)
+	rec, err := statFile(path)
+	if err != nil {
+		if offsetErr != nil {
+			return time.Time{}, "", offsetErr
+		}
+		return time.Time{}, "", err
+	}
+	return rec.Mtime, path, nil
+}
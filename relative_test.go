@@ -0,0 +1,45 @@
+package main
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestRelativeTime(t *testing.T) {
+	resetState(t)
+	dir := t.TempDir()
+	marked := time.Date(2021, 3, 29, 9, 8, 7, 0, time.UTC)
+	marker := writeFile(t, filepath.Join(dir, "deploy"), "", marked)
+	// a marker whose name looks like it ends with an offset
+	dashed := writeFile(t, filepath.Join(dir, "build-2h"), "", marked)
+
+	t.Setenv(anchorDirEnv, "")
+	tests := []struct {
+		value    string
+		want     time.Time
+		wantPath string
+	}{
+		{marker, marked, marker},
+		{marker + "+10m", marked.Add(10 * time.Minute), marker},
+		{marker + "-1.5d", marked.Add(-36 * time.Hour), marker},
+		{dashed, marked, dashed},
+	}
+	for _, tt := range tests {
+		got, path, err := relativeTime(tt.value)
+		if err != nil || !got.Equal(tt.want) || path != tt.wantPath {
+			t.Errorf("relativeTime(%s) = %s, %s, %v, want %s, %s", tt.value, got, path, err, tt.want, tt.wantPath)
+		}
+	}
+
+	// a bare name is looked for in the anchor directory
+	t.Setenv(anchorDirEnv, dir)
+	got, path, err := relativeTime("deploy+1h")
+	if err != nil || !got.Equal(marked.Add(time.Hour)) || path != marker {
+		t.Errorf("relativeTime(deploy+1h) with %s = %s, %s, %v", anchorDirEnv, got, path, err)
+	}
+
+	if _, _, err := relativeTime(filepath.Join(dir, "missing") + "+1h"); err == nil {
+		t.Errorf("a missing marker was accepted")
+	}
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// shownCount, shownSize - the number and total size of files passed to displayRecord, for -header and -footer
+var (
+	shownCount int
+	shownSize  int64
+)
+
+// reportOutput - the real output while it is being buffered by beginReport, otherwise nil
+var reportOutput io.Writer
+
+// reportBuffer - holds the listing until the header, which needs its totals, can be written
+var reportBuffer bytes.Buffer
+
+// expandReportTokens - replace {count}, {total}, and {date} in a -header or -footer
+func expandReportTokens(text string) string {
+	return strings.NewReplacer(
+		"{count}", strconv.Itoa(shownCount),
+		"{total}", Format(shownSize),
+		"{date}", formatTime(startTime),
+	).Replace(text)
+}
+
+// beginReport - output the -header, unless it uses totals that are not known until all files are processed
+// in that case, output is buffered and the header is written by endReport
+func beginReport() {
+	if len(opts.header) == 0 {
+		return
+	}
+	if strings.Contains(opts.header, "{count}") || strings.Contains(opts.header, "{total}") {
+		reportOutput = output
+		output = &reportBuffer
+		return
+	}
+	fmt.Fprintln(output, expandReportTokens(opts.header))
+}
+
+// endReport - output a buffered header and listing, followed by the -footer
+func endReport() {
+	if reportOutput != nil {
+		output = reportOutput
+		reportOutput = nil
+		fmt.Fprintln(output, expandReportTokens(opts.header))
+		output.Write(reportBuffer.Bytes())
+	}
+	if len(opts.footer) > 0 {
+		fmt.Fprintln(output, expandReportTokens(opts.footer))
+	}
+}
@@ -0,0 +1,43 @@
+package main
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestExpandReportTokens(t *testing.T) {
+	resetState(t)
+	savedCount, savedSize := shownCount, shownSize
+	t.Cleanup(func() { shownCount, shownSize = savedCount, savedSize })
+	shownCount, shownSize = 3, 1234567
+	opts.format = "rfc3339"
+	startTime = time.Date(2021, 3, 29, 9, 8, 7, 0, time.UTC)
+
+	tests := []struct {
+		text, want string
+	}{
+		{"{count} files, {total} bytes", "3 files, 1,234,567 bytes"},
+		{"as of {date}", "as of 2021-03-29T09:08:07Z"},
+		{"{count}/{count}", "3/3"},
+		{"{unknown} {COUNT}", "{unknown} {COUNT}"},
+	}
+	for _, tt := range tests {
+		if got := expandReportTokens(tt.text); got != tt.want {
+			t.Errorf("expandReportTokens(%q) = %q, want %q", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestHeaderFooter(t *testing.T) {
+	dir := t.TempDir()
+	a := writeFile(t, filepath.Join(dir, "a.txt"), "abc", time.Now())
+	b := writeFile(t, filepath.Join(dir, "b.txt"), "de", time.Now())
+
+	out, stderr, code := runGostat(t, "-header", "{count} files", "-footer", "{total} bytes", a, b)
+	lines := strings.Split(strings.TrimSuffix(out, "\n"), "\n")
+	if code != 0 || lines[0] != "2 files" || lines[len(lines)-1] != "5 bytes" {
+		t.Errorf("-header and -footer exited %d with %q: %s", code, out, stderr)
+	}
+}
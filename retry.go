@@ -0,0 +1,32 @@
+package main
+
+import (
+	"errors"
+	"io/fs"
+	"log"
+	"time"
+)
+
+// retryDelay - the wait before the first -retry, which doubles before each one after it
+const retryDelay time.Duration = 100 * time.Millisecond
+
+// retryable - return false for errors that trying again will not fix, such as a missing file
+func retryable(err error) bool {
+	return !errors.Is(err, fs.ErrNotExist) && !errors.Is(err, fs.ErrPermission)
+}
+
+// withRetry - call op until it succeeds or has been retried opts.retry times, waiting twice as long before each retry
+// this is for network file systems whose operations can fail briefly; name is the file op works on
+func withRetry(name string, op func() error) error {
+	delay := retryDelay
+	err := op()
+	for i := 0; i < opts.retry && err != nil && retryable(err); i++ {
+		if opts.verbose {
+			log.Printf("Note: retrying %s in %s: %s\n", name, delay, err)
+		}
+		time.Sleep(delay)
+		delay *= 2
+		err = op()
+	}
+	return err
+}
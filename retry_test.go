@@ -0,0 +1,48 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"testing"
+)
+
+func TestWithRetry(t *testing.T) {
+	resetState(t)
+	errBusy := errors.New("temporarily unavailable")
+	// failTwice - return an op which fails twice with err and then succeeds, counting its calls
+	failTwice := func(err error, calls *int) func() error {
+		return func() error {
+			*calls += 1
+			if *calls <= 2 {
+				return err
+			}
+			return nil
+		}
+	}
+
+	tests := []struct {
+		retry     int
+		err       error
+		wantCalls int
+		wantErr   bool
+	}{
+		{2, errBusy, 3, false},
+		{5, errBusy, 3, false},
+		{1, errBusy, 2, true},
+		{0, errBusy, 1, true},
+		{2, fmt.Errorf("stat x: %w", fs.ErrNotExist), 1, true},
+		{2, fs.ErrPermission, 1, true},
+	}
+	for _, tt := range tests {
+		opts.retry = tt.retry
+		calls := 0
+		err := withRetry("x", failTwice(tt.err, &calls))
+		if calls != tt.wantCalls || (err != nil) != tt.wantErr {
+			t.Errorf("withRetry with -retry %d and %v made %d calls returning %v, want %d calls", tt.retry, tt.err, calls, err, tt.wantCalls)
+		}
+		if err != nil && !errors.Is(err, tt.err) {
+			t.Errorf("withRetry returned %v, want the last error %v", err, tt.err)
+		}
+	}
+}
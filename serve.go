@@ -0,0 +1,96 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+)
+
+// listen - return a listener for -serve, which is a unix socket for unix:PATH
+// an address without a host, such as :8080, only listens on localhost
+func listen(addr string) (net.Listener, error) {
+	if path, found := strings.CutPrefix(addr, "unix:"); found {
+		return net.Listen("unix", path)
+	}
+	if host, port, err := net.SplitHostPort(addr); err == nil && len(host) == 0 {
+		addr = net.JoinHostPort("localhost", port)
+	}
+	return net.Listen("tcp", addr)
+}
+
+// serve - answer HTTP requests for /stat?path=FILE with the times of FILE as JSON
+func serve(addr string) error {
+	ln, err := listen(addr)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "serving file times on %s\n", ln.Addr())
+	mux := http.NewServeMux()
+	mux.HandleFunc("/stat", statHandler)
+	return http.Serve(ln, mux)
+}
+
+// globResponse - the answer to /stat for a path with wildcards, and the -json output with -max-results
+// truncated is true when more than opts.maxResults files matched and only that many are included
+type globResponse struct {
+	Files     []fileRecord `json:"files"`
+	Truncated bool         `json:"truncated"`
+}
+
+// statHandler - respond with the same JSON as -json for the file given in the path parameter
+// a path with wildcards is answered with a globResponse of every matching file
+func statHandler(w http.ResponseWriter, r *http.Request) {
+	file := r.URL.Query().Get("path")
+	if len(file) == 0 {
+		writeJSONResponse(w, http.StatusBadRequest, map[string]string{"error": "missing path parameter"})
+		return
+	}
+	if strings.ContainsAny(file, "*?[") {
+		writeJSONResponse(w, http.StatusOK, statGlob(file))
+		return
+	}
+	rec, err := statFile(file)
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, fs.ErrNotExist) {
+			status = http.StatusNotFound
+		} else if errors.Is(err, fs.ErrPermission) {
+			status = http.StatusForbidden
+		}
+		writeJSONResponse(w, status, map[string]string{"error": err.Error()})
+		return
+	}
+	writeJSONResponse(w, http.StatusOK, rec)
+}
+
+// statGlob - return the times of the files matching a wildcard, stopping after opts.maxResults
+// files which can not be read or are not selected by the filters are left out
+func statGlob(pattern string) globResponse {
+	resp := globResponse{Files: []fileRecord{}}
+	for _, file := range expandGlobs([]string{pattern}) {
+		rec, err := statFile(file)
+		if err != nil || !selected(rec) {
+			continue
+		}
+		if opts.maxResults > 0 && len(resp.Files) == opts.maxResults {
+			resp.Truncated = true
+			break
+		}
+		resp.Files = append(resp.Files, rec)
+	}
+	return resp
+}
+
+// writeJSONResponse - send v as the JSON body of a response
+func writeJSONResponse(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		logError("HTTP Error: %s\n", err)
+	}
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// getStat - request /stat from statHandler with the given path and decode the JSON response into v
+func getStat(t *testing.T, path string, v any) int {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodGet, "/stat?path="+url.QueryEscape(path), nil)
+	rr := httptest.NewRecorder()
+	statHandler(rr, req)
+	if ct := rr.Header().Get("Content-Type"); ct != "application/json" {
+		t.Errorf("Content-Type for %q = %s", path, ct)
+	}
+	if err := json.Unmarshal(rr.Body.Bytes(), v); err != nil {
+		t.Fatalf("the response for %q is not JSON: %s: %q", path, err, rr.Body.String())
+	}
+	return rr.Code
+}
+
+func TestStatHandler(t *testing.T) {
+	resetState(t)
+	opts.quietErrors = true
+	dir := t.TempDir()
+	mtime := time.Date(2021, 3, 29, 9, 8, 7, 0, time.UTC)
+	a := writeFile(t, filepath.Join(dir, "a.txt"), "abc", mtime)
+	b := writeFile(t, filepath.Join(dir, "b.txt"), "", mtime)
+
+	var rec fileRecord
+	if code := getStat(t, a, &rec); code != http.StatusOK || rec.Name != a || rec.Size != 3 || !rec.Mtime.Equal(mtime) {
+		t.Errorf("/stat for a file = %d, %+v", code, rec)
+	}
+
+	var failure map[string]string
+	if code := getStat(t, filepath.Join(dir, "missing"), &failure); code != http.StatusNotFound || len(failure["error"]) == 0 {
+		t.Errorf("/stat for a missing file = %d, %q", code, failure)
+	}
+	failure = nil
+	if code := getStat(t, "", &failure); code != http.StatusBadRequest || failure["error"] != "missing path parameter" {
+		t.Errorf("/stat without a path = %d, %q", code, failure)
+	}
+
+	var glob globResponse
+	if code := getStat(t, filepath.Join(dir, "*.txt"), &glob); code != http.StatusOK || len(glob.Files) != 2 || glob.Files[0].Name != a || glob.Files[1].Name != b || glob.Truncated {
+		t.Errorf("/stat for a wildcard = %d, %+v", code, glob)
+	}
+
+	opts.maxResults = 1
+	glob = globResponse{}
+	if code := getStat(t, filepath.Join(dir, "*.txt"), &glob); code != http.StatusOK || len(glob.Files) != 1 || glob.Files[0].Name != a || !glob.Truncated {
+		t.Errorf("/stat for a wildcard with -max-results 1 = %d, %+v", code, glob)
+	}
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+)
+
+// snapshotFields - the time stamps compared by -assert-unchanged unless -fields is given
+// access times are left out since merely reading a file can update them
+var snapshotFields = []string{"m", "b"}
+
+// loadSnapshot - read records saved with -json, keyed by file name
+// this is either an array of records or, when saved with -max-results, a globResponse holding them;
+// saved times keep the zone they were written in, so they are moved to the local, or -tz, zone for display
+func loadSnapshot(path string) (map[string]fileRecord, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var saved []fileRecord
+	if err = json.Unmarshal(data, &saved); err != nil {
+		var resp globResponse
+		if json.Unmarshal(data, &resp) != nil || resp.Files == nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		if resp.Truncated {
+			logError("Warning: %s was cut short by -max-results, later files are not in it\n", path)
+		}
+		saved = resp.Files
+	}
+	snapshot := make(map[string]fileRecord, len(saved))
+	for _, rec := range saved {
+		rec.Mtime, rec.Atime = rec.Mtime.Local(), rec.Atime.Local()
+		for _, t := range []*time.Time{rec.Btime, rec.Ctime} {
+			if t != nil {
+				*t = t.Local()
+			}
+		}
+		snapshot[rec.Name] = rec
+	}
+	return snapshot, nil
+}
+
+// writeSnapshotDiffs - list the time stamps that differ from opts.snapshot and return how many files differ
+// files are matched to the snapshot by name, so they should be given the same way as when it was saved
+func writeSnapshotDiffs() int {
+	count := 0
+	for _, rec := range records {
+		saved, found := opts.snapshot[rec.Name]
+		if !found {
+			fmt.Fprintf(output, "%s: not in snapshot\n", rec.Name)
+			count += 1
+			continue
+		}
+		was, now := saved.times(), rec.times()
+		differs := false
+		for _, field := range comparedFields(snapshotFields) {
+			w, wFound := was[field]
+			n, nFound := now[field]
+			if !wFound || !nFound || timesEqual(w, n) {
+				continue
+			}
+			fmt.Fprintf(output, "%s: %s changed from %s to %s\n", rec.Name, fieldNames[field], formatTime(w), formatTime(n))
+			differs = true
+		}
+		if differs {
+			count += 1
+		}
+	}
+	return count
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAssertUnchanged(t *testing.T) {
+	dir := t.TempDir()
+	mtime := time.Date(2021, 3, 29, 9, 8, 7, 0, time.UTC)
+	a := writeFile(t, filepath.Join(dir, "a.txt"), "", mtime)
+	b := writeFile(t, filepath.Join(dir, "b.txt"), "", mtime)
+	saved := filepath.Join(t.TempDir(), "snapshot.json")
+	if _, stderr, code := runGostat(t, "-json", "-o", saved, a, b); code != 0 {
+		t.Fatalf("saving the snapshot exited %d: %s", code, stderr)
+	}
+
+	out, stderr, code := runGostat(t, "-assert-unchanged", saved, a, b)
+	if code != 0 || len(out) > 0 {
+		t.Errorf("unchanged files exited %d with %q: %s", code, out, stderr)
+	}
+
+	// reading a file does not count as a change
+	if err := os.Chtimes(a, time.Now(), mtime); err != nil {
+		t.Fatal(err)
+	}
+	if out, _, code = runGostat(t, "-assert-unchanged", saved, a, b); code != 0 || len(out) > 0 {
+		t.Errorf("a new access time exited %d with %q", code, out)
+	}
+
+	changed := mtime.Add(time.Hour)
+	if err := os.Chtimes(b, changed, changed); err != nil {
+		t.Fatal(err)
+	}
+	c := writeFile(t, filepath.Join(dir, "c.txt"), "", mtime)
+	out, _, code = runGostat(t, "-tz", "UTC", "-format", "rfc3339", "-assert-unchanged", saved, a, b, c)
+	want := b + ": mtime changed from 2021-03-29T09:08:07Z to 2021-03-29T10:08:07Z\n" + c + ": not in snapshot\n"
+	if code != 1 || out != want {
+		t.Errorf("changed files exited %d with %q, want 1 with %q", code, out, want)
+	}
+	if strings.Contains(out, a) {
+		t.Errorf("an unchanged file was listed: %q", out)
+	}
+}
+
+func TestSnapshotFields(t *testing.T) {
+	buf := resetState(t)
+	opts.format = "rfc3339"
+	mtime := time.Date(2021, 3, 29, 9, 8, 7, 0, time.UTC)
+	savedBtime, currentBtime := mtime.Add(-time.Hour), mtime.Add(-time.Minute)
+	opts.snapshot = map[string]fileRecord{"a": {Name: "a", Mtime: mtime, Btime: &savedBtime}}
+	records = []fileRecord{{Name: "a", Mtime: mtime, Btime: &currentBtime}}
+
+	// by default a different birth time is a change
+	if count := writeSnapshotDiffs(); count != 1 || !strings.Contains(buf.String(), "a: "+birthLabel+" changed") {
+		t.Errorf("a birth time difference gave %d with %q", count, buf.String())
+	}
+
+	buf.Reset()
+	opts.compareFields = []string{"m"}
+	if count := writeSnapshotDiffs(); count != 0 || buf.Len() > 0 {
+		t.Errorf("-fields m gave %d with %q, want the birth time ignored", count, buf.String())
+	}
+	if got := comparedFields(snapshotFields); strings.Join(got, ",") != "m" {
+		t.Errorf("comparedFields with -fields m = %q", got)
+	}
+}
+
+func TestSnapshotMaxResults(t *testing.T) {
+	files := tempFiles(t, 3)
+	saved := filepath.Join(t.TempDir(), "snapshot.json")
+	if _, stderr, code := runGostat(t, append([]string{"-json", "-max-results", "2", "-o", saved}, files...)...); code != 0 {
+		t.Fatalf("saving the snapshot exited %d: %s", code, stderr)
+	}
+
+	out, stderr, code := runGostat(t, append([]string{"-assert-unchanged", saved}, files...)...)
+	if want := files[2] + ": not in snapshot\n"; code != 1 || out != want {
+		t.Errorf("a snapshot saved with -max-results exited %d with %q, want 1 with %q: %s", code, out, want, stderr)
+	}
+	if !strings.Contains(stderr, "cut short by -max-results") {
+		t.Errorf("loading a truncated snapshot gave no warning: %q", stderr)
+	}
+
+	bogus := filepath.Join(t.TempDir(), "bogus.json")
+	writeFile(t, bogus, `{"name": "a.txt"}`, time.Now())
+	if _, stderr, code := runGostat(t, "-assert-unchanged", bogus, files[0]); code == 0 || !strings.Contains(stderr, "cannot unmarshal") {
+		t.Errorf("a snapshot which is neither shape exited %d: %s", code, stderr)
+	}
+}
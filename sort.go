@@ -0,0 +1,46 @@
+package main
+
+import (
+	"strings"
+)
+
+// isDigit - return true for the ASCII digits 0 through 9
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+// naturalLess - compare two strings so that runs of digits are ordered by their numeric value,
+// putting file2 before file10; strings which are otherwise equal fall back to a lexical comparison
+func naturalLess(a, b string) bool {
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		if isDigit(a[i]) && isDigit(b[j]) {
+			startA, startB := i, j
+			for i < len(a) && isDigit(a[i]) {
+				i++
+			}
+			for j < len(b) && isDigit(b[j]) {
+				j++
+			}
+			// compare without leading zeros: a longer number is larger, otherwise compare digit by digit
+			numA := strings.TrimLeft(a[startA:i], "0")
+			numB := strings.TrimLeft(b[startB:j], "0")
+			if len(numA) != len(numB) {
+				return len(numA) < len(numB)
+			}
+			if numA != numB {
+				return numA < numB
+			}
+			continue
+		}
+		if a[i] != b[j] {
+			return a[i] < b[j]
+		}
+		i++
+		j++
+	}
+	if len(a)-i != len(b)-j {
+		return len(a)-i < len(b)-j
+	}
+	return a < b
+}
@@ -0,0 +1,39 @@
+package main
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func TestNaturalLess(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want bool
+	}{
+		{"file2", "file10", true},
+		{"file10", "file2", false},
+		{"file2", "file2", false},
+		{"a", "b", true},
+		{"file", "file1", true},
+		{"file02", "file2", true},
+		{"file2", "file02", false},
+		{"x9y", "x10a", true},
+		{"img12.jpg", "img12.png", true},
+		{"99999999999999999999", "100000000000000000000", true},
+	}
+	for _, tt := range tests {
+		if got := naturalLess(tt.a, tt.b); got != tt.want {
+			t.Errorf("naturalLess(%q, %q) = %v, want %v", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestNaturalSort(t *testing.T) {
+	names := []string{"file10.txt", "file1.txt", "file2.txt", "File3.txt", "file1b.txt"}
+	sort.SliceStable(names, func(i, j int) bool { return naturalLess(names[i], names[j]) })
+	want := []string{"File3.txt", "file1.txt", "file1b.txt", "file2.txt", "file10.txt"}
+	if !reflect.DeepEqual(names, want) {
+		t.Errorf("natural order = %q, want %q", names, want)
+	}
+}
@@ -0,0 +1,24 @@
+package main
+
+import (
+	"os"
+	"time"
+
+	"github.com/djherbis/times"
+)
+
+// stdinName - the argument which refers to whatever STDIN is connected to, use ./- for a file named -
+const stdinName = "-"
+
+// statStdin - return the file info and times of STDIN, such as a pipe, terminal, or redirected file
+func statStdin() (os.FileInfo, map[string]time.Time, error) {
+	fi, err := os.Stdin.Stat()
+	if err != nil {
+		return nil, nil, err
+	}
+	t, err := times.StatFile(os.Stdin)
+	if err != nil {
+		return nil, nil, err
+	}
+	return fi, timespecToMap(t), nil
+}
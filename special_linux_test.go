@@ -0,0 +1,19 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSpecialPaths(t *testing.T) {
+	out, stderr, code := runGostatWith(t, nil, "piped", "-show-type", "-", "/proc/self/status", "/dev/stdin")
+	if code != 0 {
+		t.Fatalf("special paths exited %d: %s", code, stderr)
+	}
+	if got, want := shownNames(out), []string{"-", "/proc/self/status", "/dev/stdin"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("special paths showed %q, want %q: %s", got, want, stderr)
+	}
+	if got := outputLine(out, "type  :"); got != "type  : fifo" {
+		t.Errorf("piped STDIN has %q, want a fifo", got)
+	}
+}
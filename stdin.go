@@ -0,0 +1,35 @@
+package main
+
+import (
+	"bufio"
+	"io"
+	"strings"
+)
+
+// readPaths - return one file name per line of r
+// when field is greater than zero, lines are split on delim and only that column (starting at 1) is used
+// blank lines and lines with too few columns are skipped
+func readPaths(r io.Reader, field int, delim string) ([]string, error) {
+	var paths []string
+	scanner := bufio.NewScanner(r)
+	lineNum := 0
+	for scanner.Scan() {
+		lineNum += 1
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if field > 0 {
+			columns := strings.Split(line, delim)
+			if field > len(columns) {
+				if len(line) > 0 {
+					logError("STDIN Warning: line %d has %d fields, skipping\n", lineNum, len(columns))
+				}
+				continue
+			}
+			line = columns[field-1]
+		}
+		if len(strings.TrimSpace(line)) == 0 {
+			continue
+		}
+		paths = append(paths, line)
+	}
+	return paths, scanner.Err()
+}
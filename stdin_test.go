@@ -0,0 +1,33 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestReadPaths(t *testing.T) {
+	resetState(t)
+	opts.quietErrors = true
+	input := "a.txt\r\n\nb c.txt\n   \n"
+	got, err := readPaths(strings.NewReader(input), 0, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"a.txt", "b c.txt"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("readPaths = %q, want %q", got, want)
+	}
+}
+
+func TestReadPathsTSV(t *testing.T) {
+	resetState(t)
+	opts.quietErrors = true
+	input := "1\ta.txt\t10\n2\tb c.txt\t20\nshort\n\n3\t\t30\n"
+	got, err := readPaths(strings.NewReader(input), 2, "\t")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"a.txt", "b c.txt"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("readPaths of column 2 = %q, want %q", got, want)
+	}
+}
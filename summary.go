@@ -0,0 +1,315 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// writeByDay - output the number of files and their total size for each calendar day of modification
+func writeByDay() {
+	counts := make(map[string]int)
+	sizes := make(map[string]int64)
+	for _, rec := range records {
+		day := rec.Mtime.Format("2006-01-02")
+		counts[day] += 1
+		sizes[day] += rec.Size
+	}
+	days := make([]string, 0, len(counts))
+	for day := range counts {
+		days = append(days, day)
+	}
+	sort.Strings(days)
+
+	w := tabwriter.NewWriter(output, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "DAY\tFILES\tSIZE")
+	for _, day := range days {
+		fmt.Fprintf(w, "%s\t%d\t%s\n", day, counts[day], Format(sizes[day]))
+	}
+	w.Flush()
+}
+
+// writeByExt - output the number of files and their total size for each file name extension
+// files without an extension are counted under (none)
+func writeByExt() {
+	counts := make(map[string]int)
+	sizes := make(map[string]int64)
+	for _, rec := range records {
+		ext := filepath.Ext(rec.Name)
+		if len(ext) == 0 {
+			ext = "(none)"
+		}
+		counts[ext] += 1
+		sizes[ext] += rec.Size
+	}
+	exts := make([]string, 0, len(counts))
+	for ext := range counts {
+		exts = append(exts, ext)
+	}
+	sort.Strings(exts)
+
+	w := tabwriter.NewWriter(output, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "EXT\tFILES\tSIZE")
+	for _, ext := range exts {
+		fmt.Fprintf(w, "%s\t%d\t%s\n", ext, counts[ext], Format(sizes[ext]))
+	}
+	w.Flush()
+}
+
+// writeStale - list files whose modify time is older than opts.stale and return how many there were
+// ages are measured from when the program started so that the boundary is the same for every file;
+// nothing is output when every file is fresh, so this can be used from cron
+func writeStale() int {
+	count := 0
+	cutoff := startTime.Add(-opts.stale)
+	for _, rec := range records {
+		if beforeBound(rec.Mtime, cutoff) {
+			age := startTime.Sub(rec.Mtime)
+			fmt.Fprintf(output, "%s: modified %s ago\n", rec.Name, age.Round(time.Second))
+			count += 1
+		}
+	}
+	return count
+}
+
+// dupeKey - files are considered duplicates when these are equal
+type dupeKey struct {
+	mtime int64
+	size  int64
+}
+
+// writeDupes - output groups of files sharing the same modify time, and with opts.dupesSize the same size
+func writeDupes() {
+	groups := make(map[dupeKey][]fileRecord)
+	for _, rec := range records {
+		key := dupeKey{mtime: rec.Mtime.UnixNano(), size: -1}
+		if opts.dupesSize {
+			key.size = rec.Size
+		}
+		groups[key] = append(groups[key], rec)
+	}
+
+	var keys []dupeKey
+	for key, group := range groups {
+		if len(group) > 1 {
+			keys = append(keys, key)
+		}
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].mtime != keys[j].mtime {
+			return keys[i].mtime < keys[j].mtime
+		}
+		return keys[i].size < keys[j].size
+	})
+
+	for _, key := range keys {
+		group := groups[key]
+		if opts.dupesSize {
+			fmt.Fprintf(output, "%s, %s bytes (%d files)\n", formatTime(group[0].Mtime), Format(key.size), len(group))
+		} else {
+			fmt.Fprintf(output, "%s (%d files)\n", formatTime(group[0].Mtime), len(group))
+		}
+		for _, rec := range group {
+			fmt.Fprintf(output, "  %s\n", rec.Name)
+		}
+	}
+}
+
+// writeGroupedByDir - output files under a heading for each directory, with directories in sorted order
+// files keep their existing order within each directory
+func writeGroupedByDir() {
+	groups := make(map[string][]fileRecord)
+	var dirs []string
+	for _, rec := range records {
+		dir := filepath.Dir(rec.Name)
+		if _, found := groups[dir]; !found {
+			dirs = append(dirs, dir)
+		}
+		groups[dir] = append(groups[dir], rec)
+	}
+	sort.Strings(dirs)
+
+	for i, dir := range dirs {
+		if i > 0 && opts.logfmt {
+			fmt.Fprintln(output)
+		}
+		fmt.Fprintf(output, "==> %s <==\n", dir)
+		if !opts.logfmt {
+			fmt.Fprintln(output)
+		}
+		for _, rec := range groups[dir] {
+			printRecord(rec, nil)
+		}
+	}
+}
+
+// summaryStats - aggregate statistics output by -summary-json
+type summaryStats struct {
+	Count       int        `json:"count"`
+	TotalSize   int64      `json:"total_size"`
+	AverageSize float64    `json:"average_size"`
+	OldestMtime *time.Time `json:"oldest_mtime,omitempty"`
+	NewestMtime *time.Time `json:"newest_mtime,omitempty"`
+}
+
+// newSummaryStats - return aggregate statistics for the given records
+func newSummaryStats(recs []fileRecord) summaryStats {
+	var stats summaryStats
+	for i := range recs {
+		rec := &recs[i]
+		stats.Count += 1
+		stats.TotalSize += rec.Size
+		if stats.OldestMtime == nil || rec.Mtime.Before(*stats.OldestMtime) {
+			stats.OldestMtime = &rec.Mtime
+		}
+		if stats.NewestMtime == nil || rec.Mtime.After(*stats.NewestMtime) {
+			stats.NewestMtime = &rec.Mtime
+		}
+	}
+	if stats.Count > 0 {
+		stats.AverageSize = float64(stats.TotalSize) / float64(stats.Count)
+	}
+	return stats
+}
+
+// writeSummaryJSON - output a single JSON object summarizing all of the records
+func writeSummaryJSON() {
+	out, err := json.Marshal(newSummaryStats(records))
+	if err != nil {
+		log.Fatalf("JSON Error: %s\n", err)
+	}
+	fmt.Fprintln(output, string(out))
+}
+
+// writeUniq - output each distinct value of the opts.uniq time stamp, the number of files having it, and the first of those files
+// files without that time stamp, such as when birth time is unavailable, are left out
+func writeUniq() {
+	counts := make(map[int64]int)
+	first := make(map[int64]fileRecord)
+	for _, rec := range records {
+		t, found := rec.times()[opts.uniq]
+		if !found {
+			continue
+		}
+		key := t.UnixNano()
+		if counts[key] == 0 {
+			first[key] = rec
+		}
+		counts[key] += 1
+	}
+	keys := make([]int64, 0, len(counts))
+	for key := range counts {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i] < keys[j] })
+
+	w := tabwriter.NewWriter(output, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "%s\tFILES\tEXAMPLE\n", strings.ToUpper(fieldNames[opts.uniq]))
+	for _, key := range keys {
+		rec := first[key]
+		fmt.Fprintf(w, "%s\t%d\t%s\n", formatTime(rec.times()[opts.uniq]), counts[key], rec.Name)
+	}
+	w.Flush()
+}
+
+// writeRelNewest - output how long before the newest matched file each file was modified
+// the newest file is shown as +0s and all others as negative offsets
+func writeRelNewest() {
+	if len(records) == 0 {
+		return
+	}
+	newest := records[0].Mtime
+	for _, rec := range records[1:] {
+		if rec.Mtime.After(newest) {
+			newest = rec.Mtime
+		}
+	}
+
+	w := tabwriter.NewWriter(output, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "OFFSET\tMTIME\tNAME")
+	for _, rec := range records {
+		offset := rec.Mtime.Sub(newest).String()
+		if offset == "0s" {
+			offset = "+0s"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\n", offset, formatTime(rec.Mtime), rec.Name)
+	}
+	w.Flush()
+}
+
+// writeAgeDays - output the age of each file's modify time in days, with two decimal places, followed by its name
+// the number comes first and is separated by a tab so the output can be read by other programs
+func writeAgeDays() {
+	for _, rec := range records {
+		days := fileAge(rec.Mtime).Hours() / 24
+		fmt.Fprintf(output, "%.2f\t%s\n", days, rec.Name)
+	}
+}
+
+// writeCapabilityReport - output how many files had each time stamp available and how many did not
+// this shows at a glance what the file systems holding the files support
+func writeCapabilityReport() {
+	fields := []string{"b", "c", "m", "a"}
+	available := make(map[string]int)
+	for _, rec := range records {
+		for field := range rec.times() {
+			available[field] += 1
+		}
+	}
+
+	w := tabwriter.NewWriter(output, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "TIME\tAVAILABLE\tMISSING")
+	for _, field := range fields {
+		fmt.Fprintf(w, "%s\t%d\t%d\n", fieldNames[field], available[field], len(records)-available[field])
+	}
+	w.Flush()
+}
+
+// histogramBuckets - the age ranges used by -histogram, each holding files younger than its limit
+// the last bucket has no limit and holds everything older
+var histogramBuckets = []struct {
+	label string
+	limit time.Duration
+}{
+	{"<1h", time.Hour},
+	{"<1d", 24 * time.Hour},
+	{"<1w", 7 * 24 * time.Hour},
+	{"<1mo", 30 * 24 * time.Hour},
+	{"older", 0},
+}
+
+// histogramWidth - the length of the longest bar output by writeHistogram
+const histogramWidth int = 40
+
+// writeHistogram - output a bar chart of how many files were last modified within each histogramBuckets range
+func writeHistogram() {
+	counts := make([]int, len(histogramBuckets))
+	for _, rec := range records {
+		age := fileAge(rec.Mtime)
+		for i, bucket := range histogramBuckets {
+			if bucket.limit == 0 || age < bucket.limit {
+				counts[i] += 1
+				break
+			}
+		}
+	}
+	most := 0
+	for _, count := range counts {
+		most = max(most, count)
+	}
+
+	w := tabwriter.NewWriter(output, 0, 0, 2, ' ', 0)
+	for i, bucket := range histogramBuckets {
+		bar := 0
+		if most > 0 {
+			bar = (counts[i]*histogramWidth + most - 1) / most
+		}
+		fmt.Fprintf(w, "%s\t%d\t%s\n", bucket.label, counts[i], strings.Repeat("#", bar))
+	}
+	w.Flush()
+}
@@ -0,0 +1,324 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// tableRows - return the whitespace separated columns of each line of tabwriter output after the header
+func tableRows(out string) [][]string {
+	var rows [][]string
+	lines := strings.Split(strings.TrimSuffix(out, "\n"), "\n")
+	for _, line := range lines[1:] {
+		rows = append(rows, strings.Fields(line))
+	}
+	return rows
+}
+
+func TestWriteByDay(t *testing.T) {
+	buf := resetState(t)
+	day1 := time.Date(2021, 3, 29, 9, 0, 0, 0, time.UTC)
+	day2 := time.Date(2021, 3, 30, 23, 59, 59, 0, time.UTC)
+	records = []fileRecord{
+		{Name: "a", Size: 100, Mtime: day2},
+		{Name: "b", Size: 200, Mtime: day1},
+		{Name: "c", Size: 300, Mtime: day1.Add(time.Hour)},
+	}
+	writeByDay()
+	got := tableRows(buf.String())
+	want := [][]string{{"2021-03-29", "2", "500"}, {"2021-03-30", "1", "100"}}
+	if len(got) != len(want) {
+		t.Fatalf("writeByDay rows = %q, want %q", got, want)
+	}
+	for i := range want {
+		if strings.Join(got[i], " ") != strings.Join(want[i], " ") {
+			t.Errorf("row %d = %q, want %q", i, got[i], want[i])
+		}
+	}
+}
+
+func TestWriteStale(t *testing.T) {
+	dir := t.TempDir()
+	fresh := writeFile(t, filepath.Join(dir, "fresh.txt"), "", time.Now())
+	stale := writeFile(t, filepath.Join(dir, "stale.txt"), "", time.Now().Add(-48*time.Hour))
+
+	stdout, stderr, code := runGostat(t, "-stale", "24h", fresh, stale)
+	if code != 1 {
+		t.Errorf("-stale with a stale file exited %d, want 1: %s", code, stderr)
+	}
+	if !strings.HasPrefix(stdout, stale+": modified 48h0m") || strings.Contains(stdout, fresh) {
+		t.Errorf("-stale listed %q, want only %s", stdout, stale)
+	}
+
+	stdout, stderr, code = runGostat(t, "-stale", "24h", fresh)
+	if code != 0 || len(stdout) > 0 {
+		t.Errorf("-stale with only a fresh file exited %d with %q, want 0 and no output: %s", code, stdout, stderr)
+	}
+}
+
+func TestWriteDupes(t *testing.T) {
+	buf := resetState(t)
+	opts.format = "rfc3339"
+	t1 := time.Date(2021, 3, 29, 9, 8, 7, 0, time.UTC)
+	t2 := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	records = []fileRecord{
+		{Name: "a", Size: 10, Mtime: t1},
+		{Name: "b", Size: 10, Mtime: t2},
+		{Name: "c", Size: 20, Mtime: t1},
+		{Name: "d", Size: 10, Mtime: t2},
+		{Name: "e", Size: 10, Mtime: t1.Add(time.Nanosecond)},
+	}
+
+	writeDupes()
+	want := "2020-01-02T03:04:05Z (2 files)\n  b\n  d\n2021-03-29T09:08:07Z (2 files)\n  a\n  c\n"
+	if got := buf.String(); got != want {
+		t.Errorf("writeDupes = %q, want %q", got, want)
+	}
+
+	buf.Reset()
+	opts.dupesSize = true
+	writeDupes()
+	want = "2020-01-02T03:04:05Z, 10 bytes (2 files)\n  b\n  d\n"
+	if got := buf.String(); got != want {
+		t.Errorf("writeDupes with -dupes-size = %q, want %q", got, want)
+	}
+}
+
+func TestWriteGroupedByDir(t *testing.T) {
+	buf := resetState(t)
+	mtime := time.Date(2021, 3, 29, 9, 8, 7, 0, time.UTC)
+	one, two := filepath.Join("z", "one"), filepath.Join("a", "two")
+	records = []fileRecord{
+		{Name: filepath.Join(one, "b.txt"), Mtime: mtime, Atime: mtime},
+		{Name: filepath.Join(two, "c.txt"), Mtime: mtime, Atime: mtime},
+		{Name: filepath.Join(one, "a.txt"), Mtime: mtime, Atime: mtime},
+	}
+
+	writeGroupedByDir()
+	var got []string
+	for _, line := range strings.Split(buf.String(), "\n") {
+		if strings.HasPrefix(line, "==> ") || strings.HasPrefix(line, "name  : ") {
+			got = append(got, line)
+		}
+	}
+	want := []string{
+		"==> " + two + " <==",
+		"name  : " + records[1].Name,
+		"==> " + one + " <==",
+		"name  : " + records[0].Name,
+		"name  : " + records[2].Name,
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("writeGroupedByDir = %q, want %q", got, want)
+	}
+}
+
+func TestNewSummaryStats(t *testing.T) {
+	oldest := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	newest := time.Date(2021, 3, 29, 9, 8, 7, 0, time.UTC)
+	stats := newSummaryStats([]fileRecord{
+		{Name: "a", Size: 10, Mtime: newest.Add(-time.Hour)},
+		{Name: "b", Size: 0, Mtime: newest},
+		{Name: "c", Size: 5, Mtime: oldest},
+	})
+	if stats.Count != 3 {
+		t.Errorf("Count = %d, want 3", stats.Count)
+	}
+	if stats.TotalSize != 15 {
+		t.Errorf("TotalSize = %d, want 15", stats.TotalSize)
+	}
+	if stats.AverageSize != 5 {
+		t.Errorf("AverageSize = %v, want 5", stats.AverageSize)
+	}
+	if stats.OldestMtime == nil || !stats.OldestMtime.Equal(oldest) {
+		t.Errorf("OldestMtime = %v, want %s", stats.OldestMtime, oldest)
+	}
+	if stats.NewestMtime == nil || !stats.NewestMtime.Equal(newest) {
+		t.Errorf("NewestMtime = %v, want %s", stats.NewestMtime, newest)
+	}
+
+	empty := newSummaryStats(nil)
+	if empty.Count != 0 || empty.TotalSize != 0 || empty.AverageSize != 0 || empty.OldestMtime != nil || empty.NewestMtime != nil {
+		t.Errorf("newSummaryStats(nil) = %+v, want all zero", empty)
+	}
+}
+
+func TestWriteUniq(t *testing.T) {
+	buf := resetState(t)
+	opts.format = "rfc3339"
+	opts.uniq = "m"
+	t1 := time.Date(2021, 3, 29, 9, 8, 7, 0, time.UTC)
+	t2 := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	records = []fileRecord{
+		{Name: "a", Mtime: t1},
+		{Name: "b", Mtime: t2},
+		{Name: "c", Mtime: t1},
+		{Name: "d", Mtime: t1},
+	}
+
+	writeUniq()
+	lines := strings.Split(buf.String(), "\n")
+	if fields := strings.Fields(lines[0]); strings.Join(fields, " ") != "MTIME FILES EXAMPLE" {
+		t.Errorf("writeUniq header = %q", lines[0])
+	}
+	got := tableRows(buf.String())
+	want := [][]string{{"2020-01-02T03:04:05Z", "1", "b"}, {"2021-03-29T09:08:07Z", "3", "a"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("writeUniq rows = %q, want %q", got, want)
+	}
+
+	// files without the time stamp are left out
+	buf.Reset()
+	opts.uniq = "b"
+	btime := t2
+	records = append(records, fileRecord{Name: "e", Mtime: t1, Btime: &btime})
+	writeUniq()
+	if got, want := tableRows(buf.String()), [][]string{{"2020-01-02T03:04:05Z", "1", "e"}}; !reflect.DeepEqual(got, want) {
+		t.Errorf("writeUniq of btime rows = %q, want %q", got, want)
+	}
+}
+
+func TestWriteRelNewest(t *testing.T) {
+	buf := resetState(t)
+	opts.format = "rfc3339"
+	newest := time.Date(2021, 3, 29, 9, 8, 7, 0, time.UTC)
+	records = []fileRecord{
+		{Name: "a", Mtime: newest.Add(-90 * time.Minute)},
+		{Name: "b", Mtime: newest},
+		{Name: "c", Mtime: newest.Add(-time.Second)},
+	}
+
+	writeRelNewest()
+	want := [][]string{
+		{"-1h30m0s", "2021-03-29T07:38:07Z", "a"},
+		{"+0s", "2021-03-29T09:08:07Z", "b"},
+		{"-1s", "2021-03-29T09:08:06Z", "c"},
+	}
+	if got := tableRows(buf.String()); !reflect.DeepEqual(got, want) {
+		t.Errorf("writeRelNewest rows = %q, want %q", got, want)
+	}
+
+	buf.Reset()
+	records = nil
+	writeRelNewest()
+	if buf.Len() > 0 {
+		t.Errorf("writeRelNewest with no files output %q", buf.String())
+	}
+}
+
+func TestWriteHistogram(t *testing.T) {
+	buf := resetState(t)
+	startTime = time.Date(2021, 3, 29, 9, 8, 7, 0, time.UTC)
+	ages := []time.Duration{30 * time.Minute, time.Hour - time.Second, time.Hour, 3 * 24 * time.Hour, 100 * 24 * time.Hour, 400 * 24 * time.Hour, 31 * 24 * time.Hour, 30 * 24 * time.Hour}
+	for i, age := range ages {
+		records = append(records, fileRecord{Name: fmt.Sprint(i), Mtime: startTime.Add(-age)})
+	}
+
+	writeHistogram()
+	var got [][]string
+	for _, line := range strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n") {
+		got = append(got, strings.Fields(line))
+	}
+	want := [][]string{
+		{"<1h", "2", strings.Repeat("#", 20)},
+		{"<1d", "1", strings.Repeat("#", 10)},
+		{"<1w", "1", strings.Repeat("#", 10)},
+		{"<1mo", "0"},
+		{"older", "4", strings.Repeat("#", 40)},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("writeHistogram = %q, want %q", got, want)
+	}
+}
+
+func TestWriteByExt(t *testing.T) {
+	buf := resetState(t)
+	records = []fileRecord{
+		{Name: "a.go", Size: 1000},
+		{Name: "Makefile", Size: 5},
+		{Name: filepath.Join("v1.2", "README"), Size: 7},
+		{Name: "b.go", Size: 500},
+		{Name: "c.tar.gz", Size: 2},
+	}
+
+	writeByExt()
+	want := [][]string{{"(none)", "2", "12"}, {".go", "2", "1,500"}, {".gz", "1", "2"}}
+	if got := tableRows(buf.String()); !reflect.DeepEqual(got, want) {
+		t.Errorf("writeByExt = %q, want %q", got, want)
+	}
+}
+
+func TestAgeDays(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Date(2025, 1, 10, 12, 0, 0, 0, time.UTC)
+	a := writeFile(t, filepath.Join(dir, "a.txt"), "", now.Add(-36*time.Hour))
+	b := writeFile(t, filepath.Join(dir, "b.txt"), "", now.Add(-10*24*time.Hour-6*time.Minute))
+	c := writeFile(t, filepath.Join(dir, "c.txt"), "", now.Add(12*time.Hour))
+
+	// runs at different moments give the same ages
+	for i := 0; i < 2; i++ {
+		out, stderr, code := runGostat(t, "-now", now.Format(time.RFC3339), "-age-days", a, b, c)
+		want := "1.50\t" + a + "\n10.00\t" + b + "\n-0.50\t" + c + "\n"
+		if code != 0 || out != want {
+			t.Errorf("-age-days exited %d with %q, want %q: %s", code, out, want, stderr)
+		}
+	}
+}
+
+func TestWriteCapabilityReport(t *testing.T) {
+	buf := resetState(t)
+	when := time.Date(2021, 3, 29, 9, 0, 0, 0, time.UTC)
+	records = []fileRecord{
+		{Name: "all", Btime: &when, Ctime: &when, Mtime: when, Atime: when},
+		{Name: "no-birth", Ctime: &when, Mtime: when, Atime: when},
+		{Name: "zip-entry", Mtime: when},
+	}
+
+	writeCapabilityReport()
+	want := [][]string{
+		{fieldNames["b"], "1", "2"},
+		{fieldNames["c"], "2", "1"},
+		{"mtime", "3", "0"},
+		{"atime", "2", "1"},
+	}
+	if got := tableRows(buf.String()); !reflect.DeepEqual(got, want) {
+		t.Errorf("writeCapabilityReport = %q, want %q", got, want)
+	}
+	if header := strings.Fields(strings.SplitN(buf.String(), "\n", 2)[0]); !reflect.DeepEqual(header, []string{"TIME", "AVAILABLE", "MISSING"}) {
+		t.Errorf("writeCapabilityReport header = %q", header)
+	}
+}
+
+func TestReportCapabilities(t *testing.T) {
+	files := tempFiles(t, 3)
+	out, stderr, code := runGostat(t, append([]string{"-report-capabilities"}, files...)...)
+	if code != 0 {
+		t.Fatalf("-report-capabilities exited %d: %s", code, stderr)
+	}
+	rows := make(map[string][]string)
+	for _, row := range tableRows(out) {
+		if len(row) != 3 {
+			t.Fatalf("-report-capabilities gave the row %q: %q", row, out)
+		}
+		rows[row[0]] = row[1:]
+	}
+	if len(rows) != 4 {
+		t.Errorf("-report-capabilities gave %q, want a row for each of the four time stamps", out)
+	}
+	// every file system has access and modify times; birth and change times depend on it
+	for _, name := range []string{"mtime", "atime"} {
+		if got := rows[name]; !reflect.DeepEqual(got, []string{"3", "0"}) {
+			t.Errorf("-report-capabilities gave %s %q, want all 3 files available", name, got)
+		}
+	}
+	for _, field := range []string{"b", "c"} {
+		got := rows[fieldNames[field]]
+		if len(got) != 2 || got[0] != "3" && got[1] != "3" {
+			t.Errorf("-report-capabilities gave %s %q, want it available for all 3 files or none", fieldNames[field], got)
+		}
+	}
+}
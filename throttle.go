@@ -0,0 +1,28 @@
+package main
+
+import (
+	"sync"
+	"time"
+)
+
+// throttleMu - guards lastOperation, since -j reads files from several goroutines
+var throttleMu sync.Mutex
+
+// lastOperation - when the most recent file system call allowed by throttle was started
+var lastOperation time.Time
+
+// throttle - wait until opts.minInterval has passed since the previous file system call
+// this measures elapsed time, so the real clock is used rather than currentClock
+func throttle() {
+	if opts.minInterval <= 0 {
+		return
+	}
+	throttleMu.Lock()
+	defer throttleMu.Unlock()
+	if !lastOperation.IsZero() {
+		if wait := opts.minInterval - time.Since(lastOperation); wait > 0 {
+			time.Sleep(wait)
+		}
+	}
+	lastOperation = time.Now()
+}
@@ -0,0 +1,45 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestThrottle(t *testing.T) {
+	resetState(t)
+	saved := lastOperation
+	t.Cleanup(func() { lastOperation = saved })
+	lastOperation = time.Time{}
+
+	// the first call does not wait, each one after it waits for the interval
+	opts.minInterval = 20 * time.Millisecond
+	start := time.Now()
+	for i := 0; i < 4; i++ {
+		throttle()
+	}
+	if elapsed := time.Since(start); elapsed < 3*opts.minInterval {
+		t.Errorf("four calls took %s, want at least %s", elapsed, 3*opts.minInterval)
+	}
+
+	opts.minInterval = 0
+	start = time.Now()
+	for i := 0; i < 100; i++ {
+		throttle()
+	}
+	if elapsed := time.Since(start); elapsed >= 20*time.Millisecond {
+		t.Errorf("without -min-interval, 100 calls took %s", elapsed)
+	}
+}
+
+func TestMinInterval(t *testing.T) {
+	files := tempFiles(t, 3)
+	interval := 30 * time.Millisecond
+	start := time.Now()
+	_, stderr, code := runGostat(t, append([]string{"-min-interval", interval.String()}, files...)...)
+	if code != 0 {
+		t.Fatalf("-min-interval exited %d: %s", code, stderr)
+	}
+	if elapsed := time.Since(start); elapsed < 2*interval {
+		t.Errorf("reading three files took %s, want at least %s", elapsed, 2*interval)
+	}
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// treeNode - a path component in the output of -tree, rec is nil for directories which were not matched themselves
+type treeNode struct {
+	name     string
+	rec      *fileRecord
+	children map[string]*treeNode
+}
+
+// child - return the child node with the given name, adding it when needed
+func (n *treeNode) child(name string) *treeNode {
+	if n.children == nil {
+		n.children = make(map[string]*treeNode)
+	}
+	c, found := n.children[name]
+	if !found {
+		c = &treeNode{name: name}
+		n.children[name] = c
+	}
+	return c
+}
+
+// sortedChildren - return the children of a node ordered by name
+func (n *treeNode) sortedChildren() []*treeNode {
+	children := make([]*treeNode, 0, len(n.children))
+	for _, c := range n.children {
+		children = append(children, c)
+	}
+	sort.Slice(children, func(i, j int) bool { return naturalLess(children[i].name, children[j].name) })
+	return children
+}
+
+// label - return the name of a node followed by its modify time, when it was matched
+func (n *treeNode) label(name string) string {
+	if n.rec == nil {
+		return name
+	}
+	return name + "  " + formatTime(n.rec.Mtime)
+}
+
+// writeTree - output the matched files as an indented tree in the style of the tree command, with modify times
+// the directories leading to the top of the tree are shown together on its first line
+func writeTree() {
+	root := &treeNode{}
+	for i := range records {
+		node := root
+		name := filepath.Clean(records[i].Name)
+		// the volume and leading separator, such as / or C:\, form the first component
+		vol := filepath.VolumeName(name)
+		name = name[len(vol):]
+		if strings.HasPrefix(name, string(filepath.Separator)) {
+			vol += string(filepath.Separator)
+		}
+		if len(vol) > 0 {
+			node = node.child(vol)
+		}
+		for _, part := range strings.Split(name, string(filepath.Separator)) {
+			if len(part) > 0 {
+				node = node.child(part)
+			}
+		}
+		node.rec = &records[i]
+	}
+
+	top, path := root, ""
+	for top.rec == nil && len(top.children) == 1 {
+		top = top.sortedChildren()[0]
+		path = filepath.Join(path, top.name)
+	}
+	if len(path) == 0 {
+		path = "."
+	}
+	fmt.Fprintln(output, top.label(path))
+	writeTreeChildren(top, "")
+}
+
+// writeTreeChildren - output the children of a node, each prefixed by the lines of the branches above it
+func writeTreeChildren(n *treeNode, indent string) {
+	children := n.sortedChildren()
+	for i, c := range children {
+		branch, next := "├── ", "│   "
+		if i == len(children)-1 {
+			branch, next = "└── ", "    "
+		}
+		fmt.Fprintln(output, indent+branch+c.label(c.name))
+		writeTreeChildren(c, indent+next)
+	}
+}
@@ -0,0 +1,38 @@
+package main
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestWriteTree(t *testing.T) {
+	buf := resetState(t)
+	opts.format = "rfc3339"
+	mtime := time.Date(2021, 3, 29, 9, 8, 7, 0, time.UTC)
+	for _, name := range []string{"project/src/util/x.go", "project/b10.txt", "project/a.txt", "project/src/main.go", "project/b2.txt"} {
+		records = append(records, fileRecord{Name: filepath.FromSlash(name), Mtime: mtime})
+	}
+
+	writeTree()
+	const T = "  2021-03-29T09:08:07Z"
+	want := "project\n" +
+		"├── a.txt" + T + "\n" +
+		"├── b2.txt" + T + "\n" +
+		"├── b10.txt" + T + "\n" +
+		"└── src\n" +
+		"    ├── main.go" + T + "\n" +
+		"    └── util\n" +
+		"        └── x.go" + T + "\n"
+	if got := buf.String(); got != want {
+		t.Errorf("writeTree =\n%s\nwant\n%s", got, want)
+	}
+
+	// a matched directory at the top shows its own time
+	buf.Reset()
+	records = []fileRecord{{Name: "top", Mtime: mtime}, {Name: filepath.Join("top", "f"), Mtime: mtime}}
+	writeTree()
+	if got, want := buf.String(), "top"+T+"\n└── f"+T+"\n"; got != want {
+		t.Errorf("writeTree =\n%s\nwant\n%s", got, want)
+	}
+}
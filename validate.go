@@ -0,0 +1,52 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// validateTargets - check before anything is changed that every selected file exists and can be written
+// args are the names given on the command line, since those without wildcards which do not exist are
+// otherwise dropped when expanded; returns one problem per file so they can all be reported at once
+func validateTargets(args, allFiles []string) []string {
+	var problems []string
+	for _, arg := range args {
+		if strings.ContainsAny(arg, "*?[") {
+			continue
+		}
+		name, err := expandTilde(arg)
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("%s: %s", arg, err))
+			continue
+		}
+		found := false
+		for _, path := range withRoots(name) {
+			if _, err := os.Lstat(path); err == nil {
+				found = true
+				break
+			}
+		}
+		if !found {
+			problems = append(problems, fmt.Sprintf("%s: does not exist", arg))
+		}
+	}
+	for _, file := range allFiles {
+		rec, err := statFile(file)
+		if err != nil {
+			if os.IsNotExist(err) {
+				problems = append(problems, fmt.Sprintf("%s: does not exist", file))
+			} else {
+				problems = append(problems, fmt.Sprintf("%s: %s", file, err))
+			}
+			continue
+		}
+		if !selected(rec) {
+			continue
+		}
+		if err = checkWritable(file); err != nil {
+			problems = append(problems, fmt.Sprintf("%s: not writable: %s", file, err))
+		}
+	}
+	return problems
+}
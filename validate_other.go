@@ -0,0 +1,24 @@
+//go:build !linux && !darwin
+
+package main
+
+import (
+	"errors"
+	"os"
+)
+
+// errReadOnly - returned by checkWritable for a file without write permission
+var errReadOnly = errors.New("file is read-only")
+
+// checkWritable - return an error when a file is marked read-only
+// without access(2), only the owner's write permission bit can be checked
+func checkWritable(file string) error {
+	info, err := os.Stat(file)
+	if err != nil {
+		return err
+	}
+	if info.Mode().Perm()&0200 == 0 {
+		return errReadOnly
+	}
+	return nil
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestValidateAll(t *testing.T) {
+	dir := t.TempDir()
+	mtime := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	writable := writeFile(t, filepath.Join(dir, "writable.txt"), "", mtime)
+	readOnly := writeFile(t, filepath.Join(dir, "read-only.txt"), "", mtime)
+	if err := os.Chmod(readOnly, 0444); err != nil {
+		t.Fatal(err)
+	}
+	missing := filepath.Join(dir, "missing.txt")
+	// root, and Windows administrators, may write to read-only files
+	checksMode := runtime.GOOS != "windows" && os.Geteuid() != 0
+
+	_, stderr, code := runGostat(t, "-validate-all", "-m", "20210329.090807", writable, readOnly, missing)
+	if code == 0 {
+		t.Errorf("-validate-all with a missing file exited 0")
+	}
+	wantProblems := []string{"Invalid: " + missing + ": does not exist"}
+	if checksMode {
+		wantProblems = append(wantProblems, "Invalid: "+readOnly+": not writable")
+	}
+	for _, want := range wantProblems {
+		if !strings.Contains(stderr, want) {
+			t.Errorf("-validate-all did not report %q:\n%s", want, stderr)
+		}
+	}
+	if strings.Contains(stderr, "Invalid: "+writable) {
+		t.Errorf("-validate-all reported a writable file:\n%s", stderr)
+	}
+	if !strings.Contains(stderr, "Error: "+strconv.Itoa(len(wantProblems))+" file(s) can not be changed, no times were changed") {
+		t.Errorf("-validate-all did not count the problems:\n%s", stderr)
+	}
+	// the run is aborted before any file is changed
+	if rec, err := newFileRecord(writable); err != nil || !rec.Mtime.Equal(mtime) {
+		t.Errorf("the writable file was changed to %s, %v", rec.Mtime, err)
+	}
+
+	resetState(t)
+	if problems := validateTargets([]string{writable}, []string{writable}); len(problems) > 0 {
+		t.Errorf("validateTargets of a writable file = %q", problems)
+	}
+}
@@ -0,0 +1,12 @@
+//go:build linux || darwin
+
+package main
+
+import (
+	"golang.org/x/sys/unix"
+)
+
+// checkWritable - return an error when the current user may not write to a file
+func checkWritable(file string) error {
+	return unix.Access(file, unix.W_OK)
+}
@@ -0,0 +1,43 @@
+package main
+
+import (
+	"io/fs"
+	"path/filepath"
+)
+
+// expandRecursive - return each file along with, for directories, everything beneath them
+// directories matched by -ignore-file are not descended into
+// with opts.dirsOnly or opts.filesOnly, only directories or only non-directories are returned
+// and with opts.maxDepth, directories that deep are not descended into
+func expandRecursive(files []string) []string {
+	var allFiles []string
+	for _, root := range files {
+		if root == stdinName {
+			allFiles = append(allFiles, root)
+			continue
+		}
+		err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				logError("Walk Error: %s\n", err)
+				return nil
+			}
+			if len(opts.ignore) > 0 && ignored(path, d.IsDir()) {
+				if d.IsDir() {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			if !(opts.dirsOnly && !d.IsDir()) && !(opts.filesOnly && d.IsDir()) {
+				allFiles = append(allFiles, path)
+			}
+			if d.IsDir() && opts.maxDepth >= 0 && pathDepth(root, path) >= opts.maxDepth {
+				return filepath.SkipDir
+			}
+			return nil
+		})
+		if err != nil {
+			logError("Walk Error: %s\n", err)
+		}
+	}
+	return allFiles
+}
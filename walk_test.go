@@ -0,0 +1,38 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestExpandRecursive(t *testing.T) {
+	resetState(t)
+	root := t.TempDir()
+	sub := filepath.Join(root, "sub")
+	if err := os.Mkdir(sub, 0755); err != nil {
+		t.Fatal(err)
+	}
+	for _, file := range []string{filepath.Join(root, "a.txt"), filepath.Join(sub, "b.txt")} {
+		if err := os.WriteFile(file, nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name                string
+		dirsOnly, filesOnly bool
+		want                []string
+	}{
+		{"all", false, false, []string{root, filepath.Join(root, "a.txt"), sub, filepath.Join(sub, "b.txt")}},
+		{"dirs-only", true, false, []string{root, sub}},
+		{"files-only", false, true, []string{filepath.Join(root, "a.txt"), filepath.Join(sub, "b.txt")}},
+	}
+	for _, tt := range tests {
+		opts.dirsOnly, opts.filesOnly = tt.dirsOnly, tt.filesOnly
+		if got := expandRecursive([]string{root}); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: expandRecursive = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,28 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"golang.org/x/sys/unix"
+)
+
+func TestListXattrs(t *testing.T) {
+	file := tempFile(t, "x.txt", "x", time.Now())
+	if err := unix.Setxattr(file, "user.gostat", []byte("hello"), 0); err != nil {
+		t.Skipf("extended attributes are not supported here: %s", err)
+	}
+	attrs, err := listXattrs(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, attr := range attrs {
+		if attr.name == "user.gostat" {
+			if attr.size != 5 {
+				t.Errorf("user.gostat has size %d, want 5", attr.size)
+			}
+			return
+		}
+	}
+	t.Errorf("listXattrs = %v, want user.gostat", attrs)
+}
@@ -0,0 +1,8 @@
+//go:build !linux && !darwin
+
+package main
+
+// listXattrs - extended attributes are not supported on this platform
+func listXattrs(file string) ([]xattr, error) {
+	return nil, errXattrUnavailable
+}
@@ -0,0 +1,38 @@
+//go:build linux || darwin
+
+package main
+
+import (
+	"strings"
+
+	"golang.org/x/sys/unix"
+)
+
+// listXattrs - return the names and sizes of a file's extended attributes
+func listXattrs(file string) ([]xattr, error) {
+	size, err := unix.Listxattr(file, nil)
+	if err != nil {
+		return nil, err
+	}
+	if size == 0 {
+		return nil, nil
+	}
+	buf := make([]byte, size)
+	size, err = unix.Listxattr(file, buf)
+	if err != nil {
+		return nil, err
+	}
+
+	var attrs []xattr
+	for _, name := range strings.Split(string(buf[:size]), "\x00") {
+		if len(name) == 0 {
+			continue
+		}
+		valueSize, err := unix.Getxattr(file, name, nil)
+		if err != nil {
+			valueSize = -1
+		}
+		attrs = append(attrs, xattr{name: name, size: valueSize})
+	}
+	return attrs, nil
+}